@@ -0,0 +1,137 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Averager computes the terms of an average taken over the iterates of an
+// orbit, which is the basis of the average-sum coloring methods. Every
+// iterate after the first is passed to the Averager in FracData, and the
+// averages of the terms are recorded in the Result.
+type Averager interface {
+	// Term returns the contribution of the iterate z to the average, which
+	// should lie in [0, 1]. zPrev and zPrev2 are the two iterates preceding
+	// z, either of which may be NaN at the start of the orbit, and c is the
+	// parameter of the iteration. A NaN term is left out of the average.
+	Term(z, zPrev, zPrev2, c complex128) float64
+}
+
+// TriangleInequalityAverage measures where each iterate z_{n+1} = w + c falls
+// within the bounds ||w| - |c|| <= |z_{n+1}| <= |w| + |c| given by the
+// triangle inequality.
+type TriangleInequalityAverage struct{}
+
+func (TriangleInequalityAverage) Term(z, zPrev, zPrev2, c complex128) float64 {
+	w := cmplx.Abs(z - c)
+	mc := cmplx.Abs(c)
+	lo := math.Abs(w - mc)
+	hi := w + mc
+	if hi-lo == 0 {
+		return math.NaN()
+	}
+	return (cmplx.Abs(z) - lo) / (hi - lo)
+}
+
+// StripeAverage averages 0.5 sin(Density arg z) + 0.5 over the iterates,
+// which draws stripes that follow the field lines of the set.
+type StripeAverage struct {
+	// Density is the number of stripes per revolution. If it is zero, it
+	// defaults to 5.
+	Density float64
+}
+
+func (s StripeAverage) Term(z, zPrev, zPrev2, c complex128) float64 {
+	density := s.Density
+	if density == 0 {
+		density = 5
+	}
+	return 0.5*math.Sin(density*cmplx.Phase(z)) + 0.5
+}
+
+// CurvatureAverage averages the angle between successive steps of an orbit,
+// |arg((z_n - z_{n-1}) / (z_{n-1} - z_{n-2}))| / pi.
+type CurvatureAverage struct{}
+
+func (CurvatureAverage) Term(z, zPrev, zPrev2, c complex128) float64 {
+	den := zPrev - zPrev2
+	if den == 0 {
+		return math.NaN()
+	}
+	return math.Abs(cmplx.Phase((z-zPrev)/den)) / math.Pi
+}
+
+// averagePlot interpolates between the averages of a Result with and without
+// its last term, using the fractional part of the smoothed escape time, so
+// that the average varies continuously across iteration bands.
+func (pb *PlotterBase) averagePlot(r *Result) float64 {
+	return pb.plot(r, func(r *Result) float64 {
+		t := 1.0
+		mod := cmplx.Abs(r.Z)
+		lgBase := pb.logDegreeInv
+		if mod > 1 && pb.Radius > 1 && lgBase > 0 && !math.IsInf(lgBase, 0) {
+			t = 1 + math.Log(math.Log(pb.Radius)/math.Log(mod))*lgBase
+			t = math.Max(0, math.Min(1, t))
+		}
+
+		if r.Stats == nil {
+			return 0
+		}
+		avg := t*r.Stats.Average + (1-t)*r.Stats.AveragePrev
+		if math.IsNaN(avg) {
+			return 0
+		}
+		avg = math.Max(0, math.Min(1, avg))
+		return float64(pb.MaxIterations-2) * avg
+	})
+}
+
+// TriangleInequalityAveragePlotter plots escaped iterates by their Triangle
+// Inequality Average. Configure installs a TriangleInequalityAverage in the
+// FracData of the fractal. Since the smooth interpolation relies on the
+// bailout radius, a large one (e.g., 1000) works best.
+type TriangleInequalityAveragePlotter struct {
+	PlotterBase
+}
+
+func (p *TriangleInequalityAveragePlotter) Configure(fd *FracData, d DomainReader) {
+	fd.Average = TriangleInequalityAverage{}
+}
+
+func (p TriangleInequalityAveragePlotter) Plot(r *Result) float64 {
+	return p.averagePlot(r)
+}
+
+// StripeAveragePlotter plots escaped iterates by their Stripe Average.
+// Configure installs a StripeAverage with the given Density in the FracData
+// of the fractal.
+type StripeAveragePlotter struct {
+	PlotterBase
+	Density float64
+}
+
+func (p *StripeAveragePlotter) Configure(fd *FracData, d DomainReader) {
+	fd.Average = StripeAverage{Density: p.Density}
+}
+
+func (p StripeAveragePlotter) Plot(r *Result) float64 {
+	return p.averagePlot(r)
+}
+
+// CurvatureAveragePlotter plots escaped iterates by their Curvature Average.
+// Configure installs a CurvatureAverage in the FracData of the fractal.
+type CurvatureAveragePlotter struct {
+	PlotterBase
+}
+
+func (p *CurvatureAveragePlotter) Configure(fd *FracData, d DomainReader) {
+	fd.Average = CurvatureAverage{}
+}
+
+func (p CurvatureAveragePlotter) Plot(r *Result) float64 {
+	return p.averagePlot(r)
+}
@@ -0,0 +1,105 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac_test
+
+import (
+	"github.com/cfdwalrus/gofrac"
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestAverager_Term(t *testing.T) {
+	nan := cmplx.NaN()
+	tests := []struct {
+		avg                 gofrac.Averager
+		z, zPrev, zPrev2, c complex128
+		want                float64
+	}{
+		{gofrac.TriangleInequalityAverage{}, 3, nan, nan, 1, 1},
+		{gofrac.TriangleInequalityAverage{}, 1 + 2i, nan, nan, 1, (math.Sqrt(5) - 1) / 2},
+		{gofrac.StripeAverage{}, 1, nan, nan, 0, 0.5},
+		{gofrac.StripeAverage{Density: 1}, 1i, nan, nan, 0, 1},
+		{gofrac.CurvatureAverage{}, 2, 1, 0, 0, 0},
+		{gofrac.CurvatureAverage{}, 0, 1, 0, 0, 1},
+		{gofrac.CurvatureAverage{}, 1i, 0, -1, 0, 0.5},
+	}
+	for _, test := range tests {
+		got := test.avg.Term(test.z, test.zPrev, test.zPrev2, test.c)
+		if math.Abs(got-test.want) > 1e-12 {
+			t.Errorf("%T: z = %v: want: %0.5f, got: %0.5f", test.avg, test.z, test.want, got)
+		}
+	}
+
+	// terms that can't be computed are NaN
+	if got := (gofrac.CurvatureAverage{}).Term(1, 0, nan, 0); !math.IsNaN(got) {
+		t.Errorf("%T: want: NaN, got: %0.5f", gofrac.CurvatureAverage{}, got)
+	}
+}
+
+func TestMandelbrot_Average(t *testing.T) {
+	c := 0.35 + 0.5i
+	m := gofrac.NewMandelbrot(1000)
+	m.SetMaxIterations(100)
+	avg := gofrac.StripeAverage{Density: 3}
+	m.SetAverager(avg)
+	r := m.Frac(c)
+	if r.Iterations >= 99 {
+		t.Fatalf("%T: want: %v to escape, got: %d iterations", m, c, r.Iterations)
+	}
+
+	// average the terms of the orbit by hand
+	var terms []float64
+	z := complex128(0)
+	for i := 0; i < r.Iterations; i++ {
+		z = z*z + c
+		terms = append(terms, avg.Term(z, 0, 0, c))
+	}
+	sum := 0.0
+	for _, term := range terms {
+		sum += term
+	}
+	n := float64(len(terms))
+	want, wantPrev := sum/n, (sum-terms[len(terms)-1])/(n-1)
+	if math.Abs(r.Stats.Average-want) > 1e-12 || math.Abs(r.Stats.AveragePrev-wantPrev) > 1e-12 {
+		t.Errorf("%T: want: %0.5f, %0.5f, got: %0.5f, %0.5f", m, want, wantPrev, r.Stats.Average, r.Stats.AveragePrev)
+	}
+}
+
+func TestAveragePlotters(t *testing.T) {
+	plotters := []gofrac.Plotter{
+		&gofrac.TriangleInequalityAveragePlotter{},
+		&gofrac.StripeAveragePlotter{Density: 4},
+		&gofrac.CurvatureAveragePlotter{},
+	}
+	for _, p := range plotters {
+		m := gofrac.NewMandelbrot(1000)
+		d, _ := gofrac.NewDomain(-2, -1, 1, 1, 30, 20)
+		if _, err := gofrac.GetImage(m, d, p, gofrac.SpectralPalette{Sweep: 360}, 100); err != nil {
+			t.Fatal(err)
+		}
+		if m.Average != nil {
+			t.Errorf("%T: want: the Averager removed after the image, got: %T", p, m.Average)
+		}
+
+		// every escaping point is plotted within the palette
+		p.(gofrac.Configurer).Configure(m.Data(), d)
+		if m.Average == nil {
+			t.Errorf("%T: want: an Averager installed, got: nil", p)
+		}
+		r, _ := gofrac.FracIt(d, m, 100)
+		for row := 0; row < 20; row++ {
+			for col := 0; col < 30; col++ {
+				res := r.At(row, col)
+				if res.Iterations == 99 {
+					continue
+				}
+				if v := p.Plot(res); !(v >= 0 && v <= 98) {
+					t.Errorf("%T: want: 0 <= v <= 98, got: %0.5f", p, v)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,308 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac
+
+import (
+	"context"
+	"errors"
+	"image"
+	"math/big"
+	"math/cmplx"
+)
+
+// BigComplex is a complex number whose real and imaginary parts are
+// arbitrary-precision floating point numbers.
+type BigComplex struct {
+	Re, Im *big.Float
+}
+
+// NewBigComplex constructs a BigComplex with value 0 and the given precision.
+func NewBigComplex(prec uint) *BigComplex {
+	return &BigComplex{
+		Re: new(big.Float).SetPrec(prec),
+		Im: new(big.Float).SetPrec(prec),
+	}
+}
+
+// Prec returns the larger of the precisions of the parts of z.
+func (z *BigComplex) Prec() uint {
+	if z.Im.Prec() > z.Re.Prec() {
+		return z.Im.Prec()
+	}
+	return z.Re.Prec()
+}
+
+// Complex128 returns the complex128 nearest to z.
+func (z *BigComplex) Complex128() complex128 {
+	re, _ := z.Re.Float64()
+	im, _ := z.Im.Float64()
+	return complex(re, im)
+}
+
+// BigFraccer is the arbitrary-precision counterpart of Fraccer. It maps a
+// point in the complex plane, given in arbitrary precision, to the result of a
+// fractal calculation.
+type BigFraccer interface {
+	// Frac performs iterations of a fractal equation for a complex number
+	// given by loc.
+	Frac(loc *BigComplex) *Result
+	SetMaxIterations(iterations int) error
+	FracDataGetter
+}
+
+// BigDomainReader reads arbitrary-precision values from a discretization of a
+// bounded 2D space.
+type BigDomainReader interface {
+	// BigAt returns the underlying coordinates of a sample (i, j) in a
+	// domain, where the sample (0, 0) is the top-left corner of the domain.
+	// If a non-existent sample is requested, an error is returned.
+	BigAt(i int, j int) (loc *BigComplex, err error)
+
+	// Dimensions returns the number of samples taken along each axis of a
+	// domain as rows and columns.
+	Dimensions() (rows int, cols int)
+}
+
+// BigDomain is a rectangular domain whose center and extent are stored in
+// arbitrary precision. It implements DomainReader as well as BigDomainReader,
+// but the coordinates returned by At are rounded to complex128.
+type BigDomain struct {
+	centerRe, centerIm *big.Float
+	width, height      *big.Float
+	xs, ys             int
+	prec               uint
+}
+
+// NewBigDomain constructs a BigDomain centered on re + i*im with the given
+// width and height, whose coordinates are computed with prec bits of
+// precision. The domain is sampled xSamples and ySamples times along the x and
+// y axes, respectively.
+func NewBigDomain(re, im, width, height *big.Float, xSamples, ySamples int, prec uint) (*BigDomain, error) {
+	if xSamples <= 0 || ySamples <= 0 {
+		return nil, errors.New("gofrac: The number of samples along any axis must be greater than zero")
+	}
+	if width.Sign() <= 0 || height.Sign() <= 0 {
+		return nil, errors.New("gofrac: the extent of a domain must be greater than zero")
+	}
+
+	return &BigDomain{
+		centerRe: re,
+		centerIm: im,
+		width:    width,
+		height:   height,
+		xs:       xSamples,
+		ys:       ySamples,
+		prec:     prec,
+	}, nil
+}
+
+func (d *BigDomain) BigAt(i int, j int) (loc *BigComplex, err error) {
+	if i < 0 || i >= d.xs || j < 0 || j >= d.ys {
+		return nil, errors.New("gofrac: sample is out of bounds")
+	}
+	return d.point(i, j), nil
+}
+
+// point maps the sample coordinates (i, j), which may lie on the far edges of
+// the domain, onto the plane.
+func (d *BigDomain) point(i int, j int) *BigComplex {
+	// offsets from the center, as fractions of the extent
+	ti := new(big.Float).SetPrec(d.prec).SetInt64(int64(2*i - d.xs))
+	ti.Quo(ti, new(big.Float).SetInt64(int64(2*d.xs)))
+	tj := new(big.Float).SetPrec(d.prec).SetInt64(int64(d.ys - 2*j))
+	tj.Quo(tj, new(big.Float).SetInt64(int64(2*d.ys)))
+
+	loc := NewBigComplex(d.prec)
+	loc.Re.Mul(ti, d.width).Add(loc.Re, d.centerRe)
+	loc.Im.Mul(tj, d.height).Add(loc.Im, d.centerIm)
+	return loc
+}
+
+// PixelOf returns the sample whose cell contains loc. The offset of loc from
+// the center of the domain is computed in full precision, but loc itself is
+// only a complex128, so deep zooms should use DeepDomain instead.
+func (d *BigDomain) PixelOf(loc complex128) (i int, j int, ok bool) {
+	frac := func(v float64, center, extent *big.Float) float64 {
+		t := new(big.Float).SetPrec(d.prec).SetFloat64(v)
+		t.Sub(t, center).Quo(t, extent)
+		f, _ := t.Float64()
+		return f
+	}
+	x := (frac(real(loc), d.centerRe, d.width) + 0.5) * float64(d.xs)
+	y := (0.5 - frac(imag(loc), d.centerIm, d.height)) * float64(d.ys)
+	return cellOf(x, y, d.xs, d.ys)
+}
+
+func (d *BigDomain) PixelBounds(i int, j int) (corners [4]complex128, err error) {
+	if i < 0 || i >= d.xs || j < 0 || j >= d.ys {
+		return corners, errors.New("gofrac: sample is out of bounds")
+	}
+	return [4]complex128{
+		d.point(i, j).Complex128(),
+		d.point(i+1, j).Complex128(),
+		d.point(i+1, j+1).Complex128(),
+		d.point(i, j+1).Complex128(),
+	}, nil
+}
+
+func (d *BigDomain) At(i int, j int) (loc complex128, err error) {
+	z, err := d.BigAt(i, j)
+	if err != nil {
+		return 0, err
+	}
+	return z.Complex128(), nil
+}
+
+func (d *BigDomain) Dimensions() (rows int, cols int) {
+	return d.ys, d.xs
+}
+
+// Deep returns a DeepDomain covering the same view as d, so that the output of
+// a DeepMandelbrot can be checked against that of a BigMandelbrot.
+func (d *BigDomain) Deep() (*DeepDomain, error) {
+	width, _ := d.width.Float64()
+	height, _ := d.height.Float64()
+	return NewDeepDomain(d.centerRe, d.centerIm, width, height, d.xs, d.ys)
+}
+
+// FracItBig is the arbitrary-precision counterpart of FracItOptions. It
+// applies the fractal calculation given by f to every sample in the domain d.
+func FracItBig(ctx context.Context, d BigDomainReader, f BigFraccer, iterations int, opts *Options) (*Results, error) {
+	err := f.SetMaxIterations(iterations)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, cols := d.Dimensions()
+	return fracIt(ctx, rows, cols, iterations, opts, func(row, col int) (*Result, error) {
+		loc, err := d.BigAt(col, row)
+		if err != nil {
+			return nil, err
+		}
+		return f.Frac(loc), nil
+	})
+}
+
+// GetImageBig is the arbitrary-precision counterpart of GetImageOptions.
+func GetImageBig(ctx context.Context, f BigFraccer, d BigDomainReader, plotter Plotter, palette ColorSampler, maxIterations int, opts *Options) (*image.RGBA, error) {
+	if maxIterations < 1 {
+		return nil, errors.New("gofrac: maximum iteration count must be greater than zero")
+	}
+
+	dr, _ := d.(DomainReader)
+	restore := configure(plotter, f.Data(), dr)
+	defer restore()
+	f.SetMaxIterations(maxIterations)
+	plotter.SetFracData(f.Data())
+
+	results, err := FracItBig(ctx, d, f, maxIterations, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return renderImage(ctx, results, plotter, palette, opts)
+}
+
+// iterateBig applies z_{n+1} = z_n^2 + c, starting from z, in arbitrary
+// precision. It is the counterpart of iterate for quadratic fractals; escape
+// is judged on the iterates rounded to complex128.
+func (f *FracData) iterateBig(z *BigComplex, c *BigComplex, prec uint) *Result {
+	crit := f.criterion(ModulusCriterion{})
+	count := 0
+	maxIt := f.MaxIterations - 1
+
+	zr := new(big.Float).SetPrec(prec).Set(z.Re)
+	zi := new(big.Float).SetPrec(prec).Set(z.Im)
+	zr2 := new(big.Float).SetPrec(prec)
+	zi2 := new(big.Float).SetPrec(prec)
+	zri := new(big.Float).SetPrec(prec)
+	round := func() complex128 {
+		x, _ := zr.Float64()
+		y, _ := zi.Float64()
+		return complex(x, y)
+	}
+
+	zc, zPrev := round(), cmplx.NaN()
+	o := f.newOrbit(zc, c.Complex128())
+	status := crit.Check(zc, zPrev, f.Radius)
+	for status == Iterating {
+		zr2.Mul(zr, zr)
+		zi2.Mul(zi, zi)
+		zri.Mul(zr, zi)
+		zr.Sub(zr2, zi2).Add(zr, c.Re)
+		zi.Add(zri, zri).Add(zi, c.Im)
+		zc, zPrev = round(), zc
+		o.visit(zc, count+1)
+
+		if count == maxIt {
+			break
+		}
+		count++
+		status = crit.Check(zc, zPrev, f.Radius)
+	}
+	return o.done(&Result{
+		Z:          zc,
+		C:          c.Complex128(),
+		Iterations: count,
+		Converged:  status == Converged,
+	})
+}
+
+// BigMandelbrot is the arbitrary-precision counterpart of Mandelbrot. It is
+// far slower than DeepMandelbrot, but makes no approximations, which makes it
+// a reference for verifying deep zooms and rendering small crops where
+// correctness matters more than speed.
+type BigMandelbrot struct {
+	Quadratic
+
+	// Prec is the precision of the iteration. If it is zero, the precision
+	// of each sample is used.
+	Prec uint
+}
+
+// NewBigMandelbrot constructs a BigMandelbrot struct with a given bailout
+// radius and precision.
+func NewBigMandelbrot(radius float64, prec uint) *BigMandelbrot {
+	return &BigMandelbrot{
+		Quadratic: NewQuadratic(radius),
+		Prec:      prec,
+	}
+}
+
+func (m BigMandelbrot) Frac(loc *BigComplex) *Result {
+	prec := m.Prec
+	if prec == 0 {
+		prec = loc.Prec()
+	}
+	return m.iterateBig(NewBigComplex(prec), loc, prec)
+}
+
+// BigJuliaQ is the arbitrary-precision counterpart of JuliaQ.
+type BigJuliaQ struct {
+	Quadratic
+	C *BigComplex
+
+	// Prec is the precision of the iteration. If it is zero, the precision
+	// of each sample is used.
+	Prec uint
+}
+
+// NewBigJuliaQ constructs a BigJuliaQ struct with a given bailout radius,
+// complex parameter c, and precision.
+func NewBigJuliaQ(radius float64, c *BigComplex, prec uint) *BigJuliaQ {
+	return &BigJuliaQ{
+		Quadratic: NewQuadratic(radius),
+		C:         c,
+		Prec:      prec,
+	}
+}
+
+func (j BigJuliaQ) Frac(loc *BigComplex) *Result {
+	prec := j.Prec
+	if prec == 0 {
+		prec = loc.Prec()
+	}
+	return j.iterateBig(loc, j.C, prec)
+}
@@ -0,0 +1,117 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac_test
+
+import (
+	"context"
+	"github.com/cfdwalrus/gofrac"
+	"math/big"
+	"testing"
+)
+
+func TestNewBigDomain(t *testing.T) {
+	re, im, one := big.NewFloat(-0.75), big.NewFloat(0.1), big.NewFloat(1)
+	if _, err := gofrac.NewBigDomain(re, im, one, one, 0, 10, 64); err == nil {
+		t.Errorf("Error not caught for bad sample count")
+	}
+	if _, err := gofrac.NewBigDomain(re, im, new(big.Float), one, 10, 10, 64); err == nil {
+		t.Errorf("Error not caught for empty domain")
+	}
+}
+
+func TestBigDomain_At(t *testing.T) {
+	// a BigDomain samples the same points as a Domain with the same extent
+	bd, _ := gofrac.NewBigDomain(big.NewFloat(0), big.NewFloat(0), big.NewFloat(4), big.NewFloat(2), 16, 8, 64)
+	flat, _ := gofrac.NewDomain(-2, -1, 2, 1, 16, 8)
+	for i := 0; i < 16; i++ {
+		for j := 0; j < 8; j++ {
+			want, _ := flat.At(i, j)
+			got, _ := bd.At(i, j)
+			if want != got {
+				t.Errorf("%T: (i, j) = (%d, %d): want: %v, got: %v", bd, i, j, want, got)
+			}
+		}
+	}
+	if _, err := bd.BigAt(0, 8); err == nil {
+		t.Errorf("%T: Error not caught for out of bounds sample", bd)
+	}
+}
+
+func TestBigMandelbrot_Frac(t *testing.T) {
+	// at depths a float64 handles, the big calculation agrees with the
+	// ordinary one
+	d, _ := gofrac.NewBigDomain(big.NewFloat(-0.75), big.NewFloat(0.1), big.NewFloat(3), big.NewFloat(2), 24, 16, 53)
+	m := gofrac.NewBigMandelbrot(2, 0)
+	got, err := gofrac.FracItBig(context.Background(), d, m, 100, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := gofrac.FracIt(d, gofrac.NewMandelbrot(2), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for row := 0; row < 16; row++ {
+		for col := 0; col < 24; col++ {
+			w, g := want.At(row, col).Iterations, got.At(row, col).Iterations
+			if w != g {
+				t.Errorf("%T: (row, col) = (%d, %d): want: %d, got: %d", m, row, col, w, g)
+			}
+		}
+	}
+}
+
+func TestBigJuliaQ_Frac(t *testing.T) {
+	c := complex(-0.8, 0.156)
+	bc := &gofrac.BigComplex{Re: big.NewFloat(real(c)), Im: big.NewFloat(imag(c))}
+	d, _ := gofrac.NewBigDomain(big.NewFloat(0), big.NewFloat(0), big.NewFloat(3), big.NewFloat(2), 12, 8, 53)
+	j := gofrac.NewBigJuliaQ(2, bc, 0)
+	got, err := gofrac.FracItBig(context.Background(), d, j, 100, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := gofrac.FracIt(d, gofrac.NewJuliaQ(2, c), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for row := 0; row < 8; row++ {
+		for col := 0; col < 12; col++ {
+			w, g := want.At(row, col).Iterations, got.At(row, col).Iterations
+			if w != g {
+				t.Errorf("%T: (row, col) = (%d, %d): want: %d, got: %d", j, row, col, w, g)
+			}
+		}
+	}
+}
+
+func TestBigMandelbrot_Deep(t *testing.T) {
+	// a deep zoom rendered by perturbation matches the brute-force result
+	re, im := big.NewFloat(0).SetPrec(128), big.NewFloat(1).SetPrec(128)
+	width, height := big.NewFloat(1.5e-30), big.NewFloat(1e-30)
+	d, _ := gofrac.NewBigDomain(re, im, width, height, 9, 6, 128)
+	deep, _ := d.Deep()
+
+	want, err := gofrac.FracItBig(context.Background(), d, gofrac.NewBigMandelbrot(1000, 0), 200, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := gofrac.NewDeepMandelbrot(1000, re, im)
+	got, err := gofrac.FracIt(deep, m, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for row := 0; row < 6; row++ {
+		for col := 0; col < 9; col++ {
+			w, g := want.At(row, col).Iterations, got.At(row, col).Iterations
+			if w != g {
+				t.Errorf("%T: (row, col) = (%d, %d): want: %d, got: %d", m, row, col, w, g)
+			}
+		}
+	}
+}
+
+func TestBigDomain_PixelOf(t *testing.T) {
+	d, _ := gofrac.NewBigDomain(big.NewFloat(-0.5), big.NewFloat(0.25), big.NewFloat(3), big.NewFloat(2), 24, 16, 64)
+	checkInvertible(t, d)
+}
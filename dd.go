@@ -0,0 +1,182 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac
+
+import (
+	"math"
+	"math/big"
+	"math/cmplx"
+)
+
+// DD is a double-double: an unevaluated sum of two float64s, Hi + Lo, with
+// |Lo| <= ulp(Hi)/2. It carries about 106 bits (roughly 32 decimal digits) of
+// precision, and its arithmetic is many times cheaper than that of big.Float.
+type DD struct {
+	Hi, Lo float64
+}
+
+// NewDD returns the DD nearest to x.
+func NewDD(x *big.Float) DD {
+	hi, _ := x.Float64()
+	prec := x.Prec()
+	if prec < 106 {
+		prec = 106
+	}
+	rem := new(big.Float).SetPrec(prec).Sub(x, big.NewFloat(hi))
+	lo, _ := rem.Float64()
+	return DD{hi, lo}
+}
+
+// twoSum returns a + b as an exact sum s + e.
+func twoSum(a, b float64) (s, e float64) {
+	s = a + b
+	bb := s - a
+	e = (a - (s - bb)) + (b - bb)
+	return s, e
+}
+
+// quickTwoSum is twoSum for |a| >= |b|.
+func quickTwoSum(a, b float64) (s, e float64) {
+	s = a + b
+	e = b - (s - a)
+	return s, e
+}
+
+// twoProd returns a * b as an exact sum p + e.
+func twoProd(a, b float64) (p, e float64) {
+	p = a * b
+	e = math.FMA(a, b, -p)
+	return p, e
+}
+
+// Add returns x + y.
+func (x DD) Add(y DD) DD {
+	s, e := twoSum(x.Hi, y.Hi)
+	t, f := twoSum(x.Lo, y.Lo)
+	e += t
+	s, e = quickTwoSum(s, e)
+	e += f
+	s, e = quickTwoSum(s, e)
+	return DD{s, e}
+}
+
+// Sub returns x - y.
+func (x DD) Sub(y DD) DD {
+	return x.Add(DD{-y.Hi, -y.Lo})
+}
+
+// Mul returns x * y.
+func (x DD) Mul(y DD) DD {
+	p, e := twoProd(x.Hi, y.Hi)
+	e += x.Hi*y.Lo + x.Lo*y.Hi
+	p, e = quickTwoSum(p, e)
+	return DD{p, e}
+}
+
+// Sqr returns x * x.
+func (x DD) Sqr() DD {
+	p, e := twoProd(x.Hi, x.Hi)
+	e += 2 * x.Hi * x.Lo
+	p, e = quickTwoSum(p, e)
+	return DD{p, e}
+}
+
+// Float64 returns the float64 nearest to x.
+func (x DD) Float64() float64 {
+	return x.Hi + x.Lo
+}
+
+// Big returns x as a big.Float.
+func (x DD) Big() *big.Float {
+	f := new(big.Float).SetPrec(106).SetFloat64(x.Hi)
+	return f.Add(f, big.NewFloat(x.Lo))
+}
+
+// DDComplex is a complex number whose parts are double-doubles.
+type DDComplex struct {
+	Re, Im DD
+}
+
+// NewDDComplex returns the DDComplex nearest to z.
+func NewDDComplex(z *BigComplex) DDComplex {
+	return DDComplex{NewDD(z.Re), NewDD(z.Im)}
+}
+
+// Complex128 returns the complex128 nearest to z.
+func (z DDComplex) Complex128() complex128 {
+	return complex(z.Re.Float64(), z.Im.Float64())
+}
+
+// iterateDD applies z_{n+1} = z_n^2 + c, starting from z, in double-double
+// precision. It is the counterpart of iterateBig; escape is judged on the
+// iterates rounded to complex128.
+func (f *FracData) iterateDD(z DDComplex, c DDComplex) *Result {
+	crit := f.criterion(ModulusCriterion{})
+	count := 0
+	maxIt := f.MaxIterations - 1
+
+	zr, zi := z.Re, z.Im
+	zc, zPrev := z.Complex128(), cmplx.NaN()
+	o := f.newOrbit(zc, c.Complex128())
+	status := crit.Check(zc, zPrev, f.Radius)
+	for status == Iterating {
+		zri := zr.Mul(zi)
+		zr = zr.Sqr().Sub(zi.Sqr()).Add(c.Re)
+		zi = DD{2 * zri.Hi, 2 * zri.Lo}.Add(c.Im)
+		zc, zPrev = complex(zr.Float64(), zi.Float64()), zc
+		o.visit(zc, count+1)
+
+		if count == maxIt {
+			break
+		}
+		count++
+		status = crit.Check(zc, zPrev, f.Radius)
+	}
+	return o.done(&Result{
+		Z:          zc,
+		C:          c.Complex128(),
+		Iterations: count,
+		Converged:  status == Converged,
+	})
+}
+
+// DDMandelbrot is the double-double counterpart of Mandelbrot. It implements
+// BigFraccer, so it may be used with a BigDomain in place of BigMandelbrot
+// whenever 106 bits of precision suffice, i.e. for zooms down to about 1e-28.
+type DDMandelbrot struct {
+	Quadratic
+}
+
+// NewDDMandelbrot constructs a DDMandelbrot struct with a given bailout
+// radius.
+func NewDDMandelbrot(radius float64) *DDMandelbrot {
+	return &DDMandelbrot{
+		Quadratic: NewQuadratic(radius),
+	}
+}
+
+func (m DDMandelbrot) Frac(loc *BigComplex) *Result {
+	return m.iterateDD(DDComplex{}, NewDDComplex(loc))
+}
+
+// DDJuliaQ is the double-double counterpart of JuliaQ. Like DDMandelbrot, it
+// implements BigFraccer.
+type DDJuliaQ struct {
+	Quadratic
+	C DDComplex
+}
+
+// NewDDJuliaQ constructs a DDJuliaQ struct with a given bailout radius and
+// complex parameter c.
+func NewDDJuliaQ(radius float64, c *BigComplex) *DDJuliaQ {
+	return &DDJuliaQ{
+		Quadratic: NewQuadratic(radius),
+		C:         NewDDComplex(c),
+	}
+}
+
+func (j DDJuliaQ) Frac(loc *BigComplex) *Result {
+	return j.iterateDD(NewDDComplex(loc), j.C)
+}
@@ -0,0 +1,84 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac_test
+
+import (
+	"context"
+	"github.com/cfdwalrus/gofrac"
+	"math/big"
+	"testing"
+)
+
+func TestDD(t *testing.T) {
+	// 1/3 is not representable as a float64, but a DD holds it to ~32 digits
+	third := new(big.Float).SetPrec(200).Quo(big.NewFloat(1), big.NewFloat(3))
+	x := gofrac.NewDD(third)
+	three := gofrac.NewDD(big.NewFloat(3))
+
+	got := x.Mul(three).Sub(gofrac.NewDD(big.NewFloat(1))).Big()
+	if f, _ := got.Float64(); f > 1e-31 || f < -1e-31 {
+		t.Errorf("%T: 3 * (1/3) - 1: want: |x| < 1e-31, got: %v", x, f)
+	}
+
+	want := new(big.Float).SetPrec(200).Mul(third, third)
+	diff := new(big.Float).SetPrec(200).Sub(want, x.Sqr().Big())
+	if f, _ := diff.Float64(); f > 1e-32 || f < -1e-32 {
+		t.Errorf("%T: (1/3)^2: want: error < 1e-32, got: %v", x, f)
+	}
+
+	sum := x.Add(x).Add(x).Big()
+	diff.Sub(sum, big.NewFloat(1))
+	if f, _ := diff.Float64(); f > 1e-31 || f < -1e-31 {
+		t.Errorf("%T: 1/3 + 1/3 + 1/3 - 1: want: |x| < 1e-31, got: %v", x, f)
+	}
+}
+
+func TestDDMandelbrot_Frac(t *testing.T) {
+	// beyond the reach of a float64, the double-double calculation agrees
+	// with the arbitrary-precision one
+	re, im := big.NewFloat(0).SetPrec(128), big.NewFloat(1).SetPrec(128)
+	d, _ := gofrac.NewBigDomain(re, im, big.NewFloat(1.5e-20), big.NewFloat(1e-20), 9, 6, 128)
+
+	want, err := gofrac.FracItBig(context.Background(), d, gofrac.NewBigMandelbrot(2, 0), 200, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := gofrac.NewDDMandelbrot(2)
+	got, err := gofrac.FracItBig(context.Background(), d, m, 200, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for row := 0; row < 6; row++ {
+		for col := 0; col < 9; col++ {
+			w, g := want.At(row, col).Iterations, got.At(row, col).Iterations
+			if w != g {
+				t.Errorf("%T: (row, col) = (%d, %d): want: %d, got: %d", m, row, col, w, g)
+			}
+		}
+	}
+}
+
+func TestDDJuliaQ_Frac(t *testing.T) {
+	c := complex(-0.8, 0.156)
+	bc := &gofrac.BigComplex{Re: big.NewFloat(real(c)), Im: big.NewFloat(imag(c))}
+	d, _ := gofrac.NewBigDomain(big.NewFloat(0), big.NewFloat(0), big.NewFloat(3), big.NewFloat(2), 12, 8, 53)
+	j := gofrac.NewDDJuliaQ(2, bc)
+	got, err := gofrac.FracItBig(context.Background(), d, j, 100, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := gofrac.FracIt(d, gofrac.NewJuliaQ(2, c), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for row := 0; row < 8; row++ {
+		for col := 0; col < 12; col++ {
+			w, g := want.At(row, col).Iterations, got.At(row, col).Iterations
+			if w != g {
+				t.Errorf("%T: (row, col) = (%d, %d): want: %d, got: %d", j, row, col, w, g)
+			}
+		}
+	}
+}
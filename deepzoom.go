@@ -0,0 +1,348 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"math/cmplx"
+)
+
+// DeepDomain is a rectangular domain centered on a point whose coordinates
+// need more precision than a float64 can hold, as is the case in deep zooms.
+// Since the extent of such a domain is tiny compared with its center, At
+// returns the offset of a sample from the center, which a float64 represents
+// accurately down to widths of around 1e-300. It is meant to be paired with
+// DeepMandelbrot.
+type DeepDomain struct {
+	centerRe, centerIm *big.Float
+	width, height      float64
+	xs, ys             int
+
+	wInv float64
+	hInv float64
+}
+
+// NewDeepDomain constructs a DeepDomain centered on re + i*im with the given
+// width and height. The domain is sampled xSamples and ySamples times along
+// the x and y axes, respectively. The precision of re and im should suffice to
+// tell neighboring samples apart.
+func NewDeepDomain(re, im *big.Float, width, height float64, xSamples, ySamples int) (*DeepDomain, error) {
+	if xSamples <= 0 || ySamples <= 0 {
+		return nil, errors.New("gofrac: The number of samples along any axis must be greater than zero")
+	}
+	if width <= 0 || height <= 0 {
+		return nil, errors.New("gofrac: the extent of a domain must be greater than zero")
+	}
+
+	return &DeepDomain{
+		centerRe: re,
+		centerIm: im,
+		width:    width,
+		height:   height,
+		xs:       xSamples,
+		ys:       ySamples,
+		wInv:     1.0 / float64(xSamples),
+		hInv:     1.0 / float64(ySamples),
+	}, nil
+}
+
+// At returns the offset of the sample (i, j) from the center of the domain.
+func (d *DeepDomain) At(i int, j int) (loc complex128, err error) {
+	if i < 0 || i >= d.xs || j < 0 || j >= d.ys {
+		return 0, errors.New("gofrac: sample is out of bounds")
+	}
+
+	return d.point(float64(i), float64(j)), nil
+}
+
+// point maps the fractional sample coordinates (i, j) onto the offsets from
+// the center of the domain.
+func (d *DeepDomain) point(i float64, j float64) complex128 {
+	re := (i*d.wInv - 0.5) * d.width
+	im := (0.5 - j*d.hInv) * d.height
+	return complex(re, im)
+}
+
+// PixelOf returns the sample whose cell contains the point at the offset loc
+// from the center of the domain.
+func (d *DeepDomain) PixelOf(loc complex128) (i int, j int, ok bool) {
+	x := (real(loc)/d.width + 0.5) * float64(d.xs)
+	y := (0.5 - imag(loc)/d.height) * float64(d.ys)
+	return cellOf(x, y, d.xs, d.ys)
+}
+
+// PixelBounds returns the corners of the cell of the sample (i, j) as offsets
+// from the center of the domain.
+func (d *DeepDomain) PixelBounds(i int, j int) (corners [4]complex128, err error) {
+	if i < 0 || i >= d.xs || j < 0 || j >= d.ys {
+		return corners, errors.New("gofrac: sample is out of bounds")
+	}
+	return cellCorners(i, j, d.point), nil
+}
+
+func (d *DeepDomain) Dimensions() (rows int, cols int) {
+	return d.ys, d.xs
+}
+
+// Center returns the center of the domain.
+func (d *DeepDomain) Center() (re, im *big.Float) {
+	return d.centerRe, d.centerIm
+}
+
+// MaxOffset returns the largest distance of any point in the domain from its
+// center, i.e. half of its diagonal.
+func (d *DeepDomain) MaxOffset() float64 {
+	return 0.5 * math.Hypot(d.width, d.height)
+}
+
+const (
+	defaultGlitchTolerance = 1e-3
+	defaultSeriesTolerance = 1e-3
+)
+
+// DeepMandelbrot renders deep zooms into the Mandelbrot set with perturbation
+// theory. A single reference orbit Z_n is computed in high precision at the
+// center of the view, and every other point c = center + dc is iterated as a
+// low-precision difference dz_n from it:
+//
+//	dz_{n+1} = 2 Z_n dz_n + dz_n^2 + dc.
+//
+// Frac therefore expects offsets from the center, such as those produced by a
+// DeepDomain, rather than absolute coordinates. The Z and C fields of a
+// Result hold the full iterate (in low precision) and the offset dc,
+// respectively.
+type DeepMandelbrot struct {
+	Quadratic
+
+	// Rebase, which is true by default, switches an orbit over to the start
+	// of the reference orbit whenever it gets closer to zero than its
+	// difference from the reference, or a glitch is detected. This avoids
+	// the loss of precision ("glitches") that perturbation otherwise
+	// suffers, and lets an orbit outlive the reference orbit.
+	Rebase bool
+
+	// GlitchTolerance is the threshold of Pauldelbrot's glitch test,
+	// |Z_n + dz_n| < GlitchTolerance |Z_n|. Glitched points are rebased if
+	// Rebase is set, and flagged in their Result otherwise.
+	GlitchTolerance float64
+
+	// SeriesRadius, if greater than zero, enables series approximation for
+	// all offsets up to that distance from the center (see
+	// DeepDomain.MaxOffset). The first iterations are then skipped by
+	// approximating dz_n with a cubic polynomial in dc.
+	SeriesRadius float64
+
+	// SeriesTolerance bounds the relative size of the cubic term of the
+	// series approximation. Smaller values skip fewer iterations but are
+	// more accurate.
+	SeriesTolerance float64
+
+	centerRe, centerIm *big.Float
+	ref                *deepReference
+}
+
+// deepReference holds the reference orbit and the coefficients of the series
+// approximation, along with the settings they were computed for.
+type deepReference struct {
+	maxIt           int
+	radius          float64
+	seriesRadius    float64
+	seriesTolerance float64
+
+	orbit   []complex128
+	a, b, c complex128
+	skip    int
+}
+
+// NewDeepMandelbrot constructs a DeepMandelbrot struct with a given bailout
+// radius and reference point re + i*im, typically the center of a DeepDomain.
+// The precision of re and im sets the precision of the reference orbit.
+func NewDeepMandelbrot(radius float64, re, im *big.Float) *DeepMandelbrot {
+	return &DeepMandelbrot{
+		Quadratic:       NewQuadratic(radius),
+		Rebase:          true,
+		GlitchTolerance: defaultGlitchTolerance,
+		SeriesTolerance: defaultSeriesTolerance,
+		centerRe:        re,
+		centerIm:        im,
+	}
+}
+
+// SetMaxIterations sets the maximum iteration count and computes the
+// reference orbit and series approximation for it. Changes to the other
+// settings of m take effect the next time it is called, which FracIt does
+// before every calculation.
+func (m *DeepMandelbrot) SetMaxIterations(n int) error {
+	if err := m.Quadratic.SetMaxIterations(n); err != nil {
+		return err
+	}
+
+	ref := m.ref
+	if ref != nil && ref.maxIt == n && ref.radius == m.Radius &&
+		ref.seriesRadius == m.SeriesRadius && ref.seriesTolerance == m.SeriesTolerance {
+		return nil
+	}
+
+	ref = &deepReference{
+		maxIt:           n,
+		radius:          m.Radius,
+		seriesRadius:    m.SeriesRadius,
+		seriesTolerance: m.SeriesTolerance,
+		orbit:           referenceOrbit(m.centerRe, m.centerIm, n, m.Radius),
+	}
+	if m.SeriesRadius > 0 {
+		ref.approximate()
+	}
+	m.ref = ref
+	return nil
+}
+
+// referenceOrbit iterates z_{n+1} = z_n^2 + c with z_0 = 0 and c = re + i*im
+// in high precision, and returns the iterates rounded to complex128. The orbit
+// ends after maxIt iterations or with the first iterate to escape.
+func referenceOrbit(re, im *big.Float, maxIt int, radius float64) []complex128 {
+	prec := re.Prec()
+	if im.Prec() > prec {
+		prec = im.Prec()
+	}
+	if prec < 64 {
+		prec = 64
+	}
+
+	newFloat := func() *big.Float {
+		return new(big.Float).SetPrec(prec)
+	}
+	zr, zi := newFloat(), newFloat()
+	zr2, zi2, zri := newFloat(), newFloat(), newFloat()
+
+	r2 := radius * radius
+	orbit := make([]complex128, 1, maxIt+1)
+	for n := 0; n < maxIt; n++ {
+		zr2.Mul(zr, zr)
+		zi2.Mul(zi, zi)
+		zri.Mul(zr, zi)
+		zr.Sub(zr2, zi2).Add(zr, re)
+		zi.Add(zri, zri).Add(zi, im)
+
+		x, _ := zr.Float64()
+		y, _ := zi.Float64()
+		z := complex(x, y)
+		orbit = append(orbit, z)
+		if mod2(z) > r2 {
+			break
+		}
+	}
+	return orbit
+}
+
+// approximate finds the number of iterations that can be skipped with the
+// series approximation dz_n = A_n dc + B_n dc^2 + C_n dc^3, where
+//
+//	A_{n+1} = 2 Z_n A_n + 1,
+//	B_{n+1} = 2 Z_n B_n + A_n^2,
+//	C_{n+1} = 2 Z_n C_n + 2 A_n B_n,
+//
+// and A_0 = B_0 = C_0 = 0.
+func (ref *deepReference) approximate() {
+	d := ref.seriesRadius
+	var a, b, c complex128
+
+	// the last iterate of the reference orbit must remain for the
+	// perturbed iteration to continue from
+	for n := 0; n < len(ref.orbit)-2; n++ {
+		z := ref.orbit[n]
+		aNext := 2*z*a + 1
+		bNext := 2*z*b + a*a
+		cNext := 2*z*c + 2*a*b
+
+		// each term must stay much smaller than the one before it
+		if cmplx.Abs(bNext)*d > ref.seriesTolerance*cmplx.Abs(aNext) ||
+			cmplx.Abs(cNext)*d > ref.seriesTolerance*cmplx.Abs(bNext) {
+			break
+		}
+		a, b, c = aNext, bNext, cNext
+		ref.skip = n + 1
+	}
+	ref.a, ref.b, ref.c = a, b, c
+}
+
+func (m DeepMandelbrot) Frac(loc complex128) *Result {
+	if m.ref == nil || len(m.ref.orbit) < 2 {
+		// without a reference orbit, e.g. if MaxIterations was set directly
+		// rather than by SetMaxIterations, the offset is iterated directly
+		// from the center rounded to complex128
+		re, _ := m.centerRe.Float64()
+		im, _ := m.centerIm.Float64()
+		r := m.q(0, complex(re, im)+loc, 0, 1)
+		r.C = loc
+		return r
+	}
+
+	crit := m.criterion(ModulusCriterion{})
+	maxIt := m.MaxIterations - 1
+	dc := loc
+
+	var dz, der complex128
+	n, count := 0, 0
+	orbit := m.ref.orbit
+	// Z_1 is the center of the view
+	c := loc + orbit[1]
+	o := m.newOrbit(0, c)
+	// the series approximation skips iterates, so it's only used when the
+	// orbit itself is of no interest
+	if o == nil && m.ref.skip > 0 && cmplx.Abs(dc) <= m.ref.seriesRadius {
+		ref := m.ref
+		dz = ((ref.c*dc+ref.b)*dc + ref.a) * dc
+		if m.Derivative {
+			der = (3*ref.c*dc+2*ref.b)*dc + ref.a
+		}
+		n, count = ref.skip, ref.skip
+	}
+
+	glitched := false
+	tol2 := m.GlitchTolerance * m.GlitchTolerance
+	z, zPrev := orbit[n]+dz, cmplx.NaN()
+	status := crit.Check(z, zPrev, m.Radius)
+	for status == Iterating {
+		if n == len(orbit)-1 {
+			// the reference orbit has run out, so restart it
+			dz, n = z, 0
+		}
+		if m.Derivative {
+			// the derivative is that of the full iterate, so it needs no
+			// perturbation
+			der = 2*z*der + 1
+		}
+		dz = 2*orbit[n]*dz + dz*dz + dc
+		n++
+		z, zPrev = orbit[n]+dz, z
+		o.visit(z, count+1)
+
+		if mod2(z) < tol2*mod2(orbit[n]) {
+			glitched = true
+		}
+		if m.Rebase && (glitched || mod2(z) < mod2(dz)) {
+			dz, n = z, 0
+			glitched = false
+		}
+
+		if count == maxIt {
+			break
+		}
+		count++
+		status = crit.Check(z, zPrev, m.Radius)
+	}
+
+	return o.done(&Result{
+		Z:          z,
+		C:          loc,
+		Iterations: count,
+		Converged:  status == Converged,
+		Glitched:   glitched,
+		DZ:         der,
+	})
+}
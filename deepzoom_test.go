@@ -0,0 +1,172 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac_test
+
+import (
+	"github.com/cfdwalrus/gofrac"
+	"math/big"
+	"math/cmplx"
+	"testing"
+)
+
+func TestNewDeepDomain(t *testing.T) {
+	re, im := big.NewFloat(-0.75), big.NewFloat(0.1)
+	if _, err := gofrac.NewDeepDomain(re, im, 1, 1, 0, 10); err == nil {
+		t.Errorf("Error not caught for bad sample count")
+	}
+	if _, err := gofrac.NewDeepDomain(re, im, 0, 1, 10, 10); err == nil {
+		t.Errorf("Error not caught for empty domain")
+	}
+}
+
+func TestDeepDomain_At(t *testing.T) {
+	// the offsets of a DeepDomain match the coordinates of a Domain with the
+	// same extent centered on the origin
+	deep, _ := gofrac.NewDeepDomain(big.NewFloat(5), big.NewFloat(7), 4, 2, 16, 8)
+	flat, _ := gofrac.NewDomain(-2, -1, 2, 1, 16, 8)
+	for i := 0; i < 16; i++ {
+		for j := 0; j < 8; j++ {
+			want, _ := flat.At(i, j)
+			got, _ := deep.At(i, j)
+			if want != got {
+				t.Errorf("%T: (i, j) = (%d, %d): want: %v, got: %v", deep, i, j, want, got)
+			}
+		}
+	}
+	if _, err := deep.At(16, 0); err == nil {
+		t.Errorf("%T: Error not caught for out of bounds sample", deep)
+	}
+}
+
+// matchDeep compares a DeepMandelbrot calculation with the ordinary
+// Mandelbrot calculation of the same view and returns the fraction of samples
+// with identical iteration counts.
+func matchDeep(t *testing.T, m *gofrac.DeepMandelbrot, center complex128, width float64, maxIt int) float64 {
+	w, h := 48, 32
+	height := width * float64(h) / float64(w)
+	deep, _ := gofrac.NewDeepDomain(big.NewFloat(real(center)), big.NewFloat(imag(center)), width, height, w, h)
+	flat, _ := gofrac.NewDomain(real(center)-width/2, imag(center)-height/2, real(center)+width/2, imag(center)+height/2, w, h)
+
+	want, err := gofrac.FracIt(flat, gofrac.NewMandelbrot(m.Radius), maxIt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := gofrac.FracIt(deep, m, maxIt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matches := 0
+	for row := 0; row < h; row++ {
+		for col := 0; col < w; col++ {
+			if want.At(row, col).Iterations == got.At(row, col).Iterations {
+				matches++
+			}
+		}
+	}
+	return float64(matches) / float64(w*h)
+}
+
+func TestDeepMandelbrot_Frac(t *testing.T) {
+	center := -0.7436438870371587 + 0.13182590420531198i
+	re, im := big.NewFloat(real(center)), big.NewFloat(imag(center))
+
+	// at depths a float64 handles, perturbation agrees with brute force
+	m := gofrac.NewDeepMandelbrot(1000, re, im)
+	if got := matchDeep(t, m, center, 1e-6, 2000); got < 0.99 {
+		t.Errorf("%T: want: >= 99%% matching samples, got: %0.1f%%", m, 100*got)
+	}
+
+	// ... with a reference orbit that escapes early, too
+	m = gofrac.NewDeepMandelbrot(1000, big.NewFloat(0.3), big.NewFloat(0.5))
+	if got := matchDeep(t, m, 0.3+0.5i, 0.05, 500); got < 0.99 {
+		t.Errorf("%T: escaping reference: want: >= 99%% matching samples, got: %0.1f%%", m, 100*got)
+	}
+
+	// the series approximation skips iterations without changing the result
+	m = gofrac.NewDeepMandelbrot(1000, re, im)
+	m.SeriesRadius = 1e-6
+	if got := matchDeep(t, m, center, 1e-6, 2000); got < 0.99 {
+		t.Errorf("%T: series: want: >= 99%% matching samples, got: %0.1f%%", m, 100*got)
+	}
+}
+
+func TestDeepMandelbrot_Frac_NoReference(t *testing.T) {
+	center := 0.3 + 0.5i
+	m := gofrac.NewDeepMandelbrot(1000, big.NewFloat(real(center)), big.NewFloat(imag(center)))
+
+	// without SetMaxIterations there is no reference orbit, and offsets are
+	// iterated directly
+	m.MaxIterations = 500
+	flat := gofrac.NewMandelbrot(1000)
+	_ = flat.SetMaxIterations(500)
+	for _, dc := range []complex128{0, 0.01, -0.02i, 0.5 - 0.5i} {
+		want := flat.Frac(center + dc)
+		got := m.Frac(dc)
+		if got.Iterations != want.Iterations || got.C != dc {
+			t.Errorf("%T: dc = %v: want: %d iterations, got: %d (C = %v)", m, dc, want.Iterations, got.Iterations, got.C)
+		}
+	}
+}
+
+func TestDeepMandelbrot_DeepZoom(t *testing.T) {
+	// c = i is a Misiurewicz point, around which the set is self-similar at
+	// every scale, so a view 1e-30 wide (far beyond float64) still contains
+	// plenty of structure
+	re := new(big.Float).SetPrec(128)
+	im := new(big.Float).SetPrec(128).SetInt64(1)
+	d, _ := gofrac.NewDeepDomain(re, im, 1e-30, 1e-30, 15, 15)
+
+	m := gofrac.NewDeepMandelbrot(1000, re, im)
+	m.SeriesRadius = d.MaxOffset()
+	r, err := gofrac.FracIt(d, m, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the samples must have been told apart, and (since none of them is i
+	// itself) they all escape
+	counts := map[int]bool{}
+	for row := 0; row < 15; row++ {
+		for col := 0; col < 15; col++ {
+			counts[r.At(row, col).Iterations] = true
+		}
+	}
+	if len(counts) < 3 || counts[999] {
+		t.Errorf("%T: want: several distinct iteration counts, got: %v", m, counts)
+	}
+}
+
+func TestDeepMandelbrot_Derivative(t *testing.T) {
+	// at a depth a float64 handles, the derivative of the perturbed iterates
+	// matches that of the ordinary calculation
+	center := -0.7436438870371587 + 0.13182590420531198i
+	re, im := big.NewFloat(real(center)), big.NewFloat(imag(center))
+	d, _ := gofrac.NewDeepDomain(re, im, 1e-6, 1e-6, 8, 8)
+
+	m := gofrac.NewDeepMandelbrot(1000, re, im)
+	m.SeriesRadius = d.MaxOffset()
+	m.SetDerivative(true)
+	m.SetMaxIterations(2000)
+	flat := gofrac.NewMandelbrot(1000)
+	flat.SetDerivative(true)
+	flat.SetMaxIterations(2000)
+
+	for i := 0; i < 8; i++ {
+		offset, _ := d.At(i, i)
+		want, got := flat.Frac(center+offset), m.Frac(offset)
+		if want.Iterations != got.Iterations || want.Iterations == 1999 {
+			continue
+		}
+		if diff := cmplx.Abs(got.DZ - want.DZ); diff > 1e-2*cmplx.Abs(want.DZ) {
+			t.Errorf("%T: offset = %v: want: %v, got: %v", m, offset, want.DZ, got.DZ)
+		}
+	}
+}
+
+func TestDeepDomain_PixelOf(t *testing.T) {
+	d, _ := gofrac.NewDeepDomain(big.NewFloat(5), big.NewFloat(7), 1e-20, 1e-20, 16, 8)
+	checkInvertible(t, d)
+}
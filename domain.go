@@ -6,6 +6,8 @@ package gofrac
 
 import (
 	"errors"
+	"math"
+	"math/cmplx"
 )
 
 // DomainReader reads values from a discretization of a bounded 2D space.
@@ -20,12 +22,51 @@ type DomainReader interface {
 	Dimensions() (rows int, cols int)
 }
 
+// InvertibleDomain is a DomainReader that can also map points in the complex
+// plane back to its samples. Every sample is the top-left corner of a cell
+// that reaches to the samples to its right and below it, and the cells tile
+// the domain.
+type InvertibleDomain interface {
+	DomainReader
+
+	// PixelOf returns the sample (i, j) whose cell contains loc. If loc lies
+	// outside of the domain, ok is false.
+	PixelOf(loc complex128) (i int, j int, ok bool)
+
+	// PixelBounds returns the corners of the cell of the sample (i, j): its
+	// top-left (the sample itself), top-right, bottom-right, and bottom-left
+	// corners, in that order. If a non-existent sample is requested, an
+	// error is returned.
+	PixelBounds(i int, j int) (corners [4]complex128, err error)
+}
+
+// cellCorners returns the corners of the cell of the sample (i, j) of a
+// domain, given a function that maps fractional sample coordinates onto the
+// plane.
+func cellCorners(i int, j int, point func(i, j float64) complex128) [4]complex128 {
+	x, y := float64(i), float64(j)
+	return [4]complex128{point(x, y), point(x+1, y), point(x+1, y+1), point(x, y+1)}
+}
+
+// cellOf returns the sample whose cell contains the point with fractional
+// sample coordinates (x, y) in a domain of xs by ys samples.
+func cellOf(x float64, y float64, xs int, ys int) (i int, j int, ok bool) {
+	fi, fj := math.Floor(x), math.Floor(y)
+	if !(fi >= 0 && fi < float64(xs) && fj >= 0 && fj < float64(ys)) {
+		return 0, 0, false
+	}
+	return int(fi), int(fj), true
+}
+
 // Domain stores bounds and sampling information over a rectangular 2D surface.
 //
 // The lower-left corner of the domain is stored in (x0, y0) and reaches to the
 // upper-right corner at (x0+xDist, y0+yDist). Along the x and y axes, xs and
 // ys samples are taken, respectively. The inverses of xDist and yDist are
 // stored in wInv and hInv, respectively, to speed up computation.
+//
+// A domain may also be rotated counterclockwise about its center by angle
+// radians, in which case rot stores the corresponding unit complex number.
 type Domain struct {
 	x0, y0       float64
 	xDist, yDist float64
@@ -33,6 +74,9 @@ type Domain struct {
 
 	wInv float64
 	hInv float64
+
+	angle float64
+	rot   complex128
 }
 
 func (r *Domain) At(i int, j int) (loc complex128, err error) {
@@ -40,13 +84,39 @@ func (r *Domain) At(i int, j int) (loc complex128, err error) {
 		return 0, errors.New("gofrac: sample is out of bounds")
 	}
 
-	ti := float64(i) * r.wInv
+	return r.point(float64(i), float64(j)), nil
+}
+
+// point maps the fractional sample coordinates (i, j) onto the plane.
+func (r *Domain) point(i float64, j float64) complex128 {
+	ti := i * r.wInv
 	re := ti*r.xDist + r.x0
 
-	tj := 1.0 - float64(j)*r.hInv
+	tj := 1.0 - j*r.hInv
 	im := tj*r.yDist + r.y0
 
-	return complex(re, im), nil
+	if r.angle != 0 {
+		c := r.Center()
+		return c + r.rot*(complex(re, im)-c)
+	}
+	return complex(re, im)
+}
+
+func (r *Domain) PixelOf(loc complex128) (i int, j int, ok bool) {
+	if r.angle != 0 {
+		c := r.Center()
+		loc = c + (loc-c)/r.rot
+	}
+	x := (real(loc) - r.x0) / r.xDist * float64(r.xs)
+	y := (1 - (imag(loc)-r.y0)/r.yDist) * float64(r.ys)
+	return cellOf(x, y, r.xs, r.ys)
+}
+
+func (r *Domain) PixelBounds(i int, j int) (corners [4]complex128, err error) {
+	if i < 0 || i >= r.xs || j < 0 || j >= r.ys {
+		return corners, errors.New("gofrac: sample is out of bounds")
+	}
+	return cellCorners(i, j, r.point), nil
 }
 
 func (r *Domain) Dimensions() (rows int, cols int) {
@@ -75,3 +145,139 @@ func NewDomain(x0, y0, x1, y1 float64, xSamples, ySamples int) (d *Domain, err e
 		hInv:  1.0 / float64(ySamples),
 	}, nil
 }
+
+// NewView constructs a rectangular 2D domain with square samples from a
+// description of the view it shows.
+//
+// center is the center of the view, and width is its extent along the x axis
+// of the image, whose height follows from the aspect ratio of the samples.
+// The view is rotated counterclockwise by angle radians about its center.
+// The domain is sampled xSamples and ySamples times along the x and y axes of
+// the image, respectively.
+//
+// A view that is magnified m times relative to one of width w has a width of
+// w/m.
+func NewView(center complex128, width float64, angle float64, xSamples, ySamples int) (d *Domain, err error) {
+	if width <= 0 {
+		return nil, errors.New("gofrac: the extent of a domain must be greater than zero")
+	}
+	height := width
+	if xSamples > 0 {
+		height = width * float64(ySamples) / float64(xSamples)
+	}
+
+	d, err = NewDomain(real(center)-width/2, imag(center)-height/2, real(center)+width/2, imag(center)+height/2, xSamples, ySamples)
+	if err != nil {
+		return nil, err
+	}
+	d.setAngle(angle)
+	return d, nil
+}
+
+func (r *Domain) setAngle(angle float64) {
+	r.angle = angle
+	r.rot = cmplx.Rect(1, angle)
+}
+
+// Center returns the center of the domain.
+func (r *Domain) Center() complex128 {
+	return complex(r.x0+r.xDist/2, r.y0+r.yDist/2)
+}
+
+// Angle returns the counterclockwise rotation of the domain about its center,
+// in radians.
+func (r *Domain) Angle() float64 {
+	return r.angle
+}
+
+// Zoom returns a copy of the domain magnified by factor about its center. A
+// factor greater than one zooms in, and one between zero and one zooms out.
+// An error is returned if factor isn't greater than zero.
+func (r *Domain) Zoom(factor float64) (*Domain, error) {
+	return r.ZoomAt(r.Center(), factor)
+}
+
+// ZoomAt returns a copy of the domain magnified by factor about the point loc,
+// which stays where it is in the image. This is what click-to-zoom wants. An
+// error is returned if factor isn't greater than zero.
+func (r *Domain) ZoomAt(loc complex128, factor float64) (*Domain, error) {
+	if !(factor > 0) {
+		return nil, errors.New("gofrac: the zoom factor must be greater than zero")
+	}
+
+	// the corner moves toward loc in the unrotated frame of the domain
+	c := r.Center()
+	p := loc
+	if r.angle != 0 {
+		p = c + (loc-c)/r.rot
+	}
+	corner := complex(r.x0, r.y0)
+	corner = p + (corner-p)/complex(factor, 0)
+
+	d := *r
+	d.xDist /= factor
+	d.yDist /= factor
+	d.x0, d.y0 = real(corner), imag(corner)
+
+	// the new center, which is what the rotation is about, must be rotated
+	// into place
+	if r.angle != 0 {
+		nc := d.Center()
+		rotated := c + r.rot*(nc-c)
+		d.x0 += real(rotated - nc)
+		d.y0 += imag(rotated - nc)
+	}
+	return &d, nil
+}
+
+// Pan returns a copy of the domain moved by delta in the complex plane.
+func (r *Domain) Pan(delta complex128) *Domain {
+	d := *r
+	d.x0 += real(delta)
+	d.y0 += imag(delta)
+	return &d
+}
+
+// Rotate returns a copy of the domain rotated counterclockwise by angle
+// radians about its center.
+func (r *Domain) Rotate(angle float64) *Domain {
+	d := *r
+	d.setAngle(r.angle + angle)
+	return &d
+}
+
+// PixelSize estimates the distance between neighboring samples of d near its
+// center. For domains whose samples aren't square, it returns the geometric
+// mean of the spacings along each axis. It returns 0 if d has only a single
+// sample.
+func PixelSize(d DomainReader) float64 {
+	rows, cols := d.Dimensions()
+	i, j := cols/2, rows/2
+	loc, err := d.At(i, j)
+	if err != nil {
+		return 0
+	}
+
+	spacing := func(i, j int) float64 {
+		next, err := d.At(i, j)
+		if err != nil {
+			return 0
+		}
+		return cmplx.Abs(next - loc)
+	}
+	var dx, dy float64
+	if cols > 1 {
+		dx = spacing(i-1, j)
+	}
+	if rows > 1 {
+		dy = spacing(i, j-1)
+	}
+
+	switch {
+	case dx > 0 && dy > 0:
+		return math.Sqrt(dx * dy)
+	case dx > 0:
+		return dx
+	}
+	return dy
+}
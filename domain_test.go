@@ -6,6 +6,8 @@ package gofrac_test
 
 import (
 	"github.com/cfdwalrus/gofrac"
+	"math"
+	"math/cmplx"
 	"testing"
 )
 
@@ -72,3 +74,125 @@ func TestDomain_Dimensions(t *testing.T) {
 		}
 	}
 }
+
+func TestPixelSize(t *testing.T) {
+	d, _ := gofrac.NewDomain(-2, -1, 2, 1, 40, 20)
+	want := 0.1
+	if got := gofrac.PixelSize(d); math.Abs(got-want) > 1e-12 {
+		t.Errorf("%T: want: %v, got: %v", d, want, got)
+	}
+
+	d, _ = gofrac.NewDomain(-2, -1, 2, 1, 1, 1)
+	if got := gofrac.PixelSize(d); got != 0 {
+		t.Errorf("%T: want: %v, got: %v", d, 0, got)
+	}
+}
+
+func TestNewView(t *testing.T) {
+	if _, err := gofrac.NewView(0, 0, 0, 10, 10); err == nil {
+		t.Errorf("Error not caught for empty view")
+	}
+	if _, err := gofrac.NewView(0, 1, 0, 0, 10); err == nil {
+		t.Errorf("Error not caught for bad sample count")
+	}
+
+	// an unrotated view is an ordinary domain with square samples
+	d, _ := gofrac.NewView(-0.5+0.25i, 3, 0, 60, 40)
+	want, _ := gofrac.NewDomain(-2, -0.75, 1, 1.25, 60, 40)
+	for _, ij := range [][2]int{{0, 0}, {59, 0}, {30, 20}, {17, 39}} {
+		w, _ := want.At(ij[0], ij[1])
+		g, _ := d.At(ij[0], ij[1])
+		if cmplx.Abs(w-g) > 1e-12 {
+			t.Errorf("%T: (i, j) = (%d, %d): want: %v, got: %v", d, ij[0], ij[1], w, g)
+		}
+	}
+
+	// a quarter turn maps the x axis of the image onto the imaginary axis
+	d, _ = gofrac.NewView(1i, 2, math.Pi/2, 20, 10)
+	z0, _ := d.At(10, 5)
+	z1, _ := d.At(11, 5)
+	z2, _ := d.At(10, 6)
+	if cmplx.Abs(z0-1i) > 1e-12 || cmplx.Abs(z1-z0-0.1i) > 1e-12 || cmplx.Abs(z2-z0-0.1) > 1e-12 {
+		t.Errorf("%T: want: center %v, steps %v and %v, got: %v, %v and %v", d, 1i, 0.1i, 0.1, z0, z1-z0, z2-z0)
+	}
+}
+
+func TestDomain_Transformations(t *testing.T) {
+	d, _ := gofrac.NewView(-0.5, 3, 0.3, 60, 40)
+
+	in, err := d.Zoom(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := in.Center(); cmplx.Abs(got - -0.5) > 1e-12 {
+		t.Errorf("%T: Zoom: want: center %v, got: %v", d, -0.5, got)
+	}
+	if got, want := gofrac.PixelSize(in), gofrac.PixelSize(d)/4; math.Abs(got-want) > 1e-12 {
+		t.Errorf("%T: Zoom: want: pixel size %v, got: %v", d, want, got)
+	}
+	if got := d.Pan(1 + 1i).Center(); cmplx.Abs(got-(0.5+1i)) > 1e-12 {
+		t.Errorf("%T: Pan: want: center %v, got: %v", d, 0.5+1i, got)
+	}
+	if got := d.Rotate(0.2).Angle(); math.Abs(got-0.5) > 1e-12 {
+		t.Errorf("%T: Rotate: want: angle %v, got: %v", d, 0.5, got)
+	}
+
+	// zooming in on a sample keeps it in place
+	loc, _ := d.At(12, 31)
+	zoomed, _ := d.ZoomAt(loc, 8)
+	if got, _ := zoomed.At(12, 31); cmplx.Abs(got-loc) > 1e-12 {
+		t.Errorf("%T: ZoomAt: want: %v, got: %v", d, loc, got)
+	}
+	if got := zoomed.Angle(); got != d.Angle() {
+		t.Errorf("%T: ZoomAt: want: angle %v, got: %v", d, d.Angle(), got)
+	}
+
+	for _, factor := range []float64{0, -2, math.NaN()} {
+		if _, err := d.Zoom(factor); err == nil {
+			t.Errorf("%T: Error not caught for zoom factor %v", d, factor)
+		}
+	}
+}
+
+// checkInvertible checks that the cells of d line up with its samples.
+func checkInvertible(t *testing.T, d gofrac.InvertibleDomain) {
+	t.Helper()
+	rows, cols := d.Dimensions()
+	for j := 0; j < rows; j += 3 {
+		for i := 0; i < cols; i += 3 {
+			corners, err := d.PixelBounds(i, j)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if loc, _ := d.At(i, j); corners[0] != loc {
+				t.Errorf("%T: (i, j) = (%d, %d): want: top-left corner %v, got: %v", d, i, j, loc, corners[0])
+			}
+
+			// the middle of a cell belongs to it
+			mid := (corners[0] + corners[2]) / 2
+			if gi, gj, ok := d.PixelOf(mid); !ok || gi != i || gj != j {
+				t.Errorf("%T: PixelOf(%v): want: (%d, %d), got: (%d, %d, %v)", d, mid, i, j, gi, gj, ok)
+			}
+		}
+	}
+
+	// beyond the corners of the domain there are no samples
+	first, _ := d.PixelBounds(0, 0)
+	last, _ := d.PixelBounds(cols-1, rows-1)
+	for _, loc := range []complex128{2*first[0] - first[2], 2*last[2] - last[0]} {
+		if i, j, ok := d.PixelOf(loc); ok {
+			t.Errorf("%T: PixelOf(%v): want: outside, got: (%d, %d)", d, loc, i, j)
+		}
+	}
+	if _, err := d.PixelBounds(cols, 0); err == nil {
+		t.Errorf("%T: Error not caught for out of bounds sample", d)
+	}
+}
+
+func TestDomain_PixelOf(t *testing.T) {
+	d, _ := gofrac.NewDomain(-2, -1, 1, 1, 30, 20)
+	checkInvertible(t, d)
+
+	v, _ := gofrac.NewView(0.3-0.2i, 2, 1.1, 25, 17)
+	checkInvertible(t, v)
+}
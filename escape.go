@@ -0,0 +1,138 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Status describes the state of an orbit as judged by an EscapeCriterion.
+type Status int
+
+const (
+	// Iterating means that the orbit should be iterated further.
+	Iterating Status = iota
+
+	// Escaped means that the orbit has left the bailout region.
+	Escaped
+
+	// Converged means that the orbit has settled on a finite attractor.
+	Converged
+)
+
+// EscapeCriterion decides when the iteration of an orbit should stop. Any
+// fractal that iterates until escape or convergence consults the criterion
+// stored in its FracData, and falls back to its own default if there is none.
+type EscapeCriterion interface {
+	// Check judges the iterate z, whose predecessor is zPrev, against the
+	// bailout radius of a fractal calculation.
+	Check(z complex128, zPrev complex128, radius float64) Status
+}
+
+// ModulusCriterion is the usual escape test, |z| > radius.
+type ModulusCriterion struct{}
+
+func (ModulusCriterion) Check(z complex128, _ complex128, radius float64) Status {
+	if mod2(z) > radius*radius {
+		return Escaped
+	}
+	return Iterating
+}
+
+// RealCriterion tests the real part of an iterate, |Re(z)| > radius.
+type RealCriterion struct{}
+
+func (RealCriterion) Check(z complex128, _ complex128, radius float64) Status {
+	if math.Abs(real(z)) > radius {
+		return Escaped
+	}
+	return Iterating
+}
+
+// ImagCriterion tests the imaginary part of an iterate, |Im(z)| > radius.
+type ImagCriterion struct{}
+
+func (ImagCriterion) Check(z complex128, _ complex128, radius float64) Status {
+	if math.Abs(imag(z)) > radius {
+		return Escaped
+	}
+	return Iterating
+}
+
+// ManhattanCriterion tests the taxicab norm of an iterate,
+// |Re(z)| + |Im(z)| > radius.
+type ManhattanCriterion struct{}
+
+func (ManhattanCriterion) Check(z complex128, _ complex128, radius float64) Status {
+	if math.Abs(real(z))+math.Abs(imag(z)) > radius {
+		return Escaped
+	}
+	return Iterating
+}
+
+// MaxNormCriterion tests the maximum norm of an iterate,
+// max(|Re(z)|, |Im(z)|) > radius.
+type MaxNormCriterion struct{}
+
+func (MaxNormCriterion) Check(z complex128, _ complex128, radius float64) Status {
+	if math.Max(math.Abs(real(z)), math.Abs(imag(z))) > radius {
+		return Escaped
+	}
+	return Iterating
+}
+
+// AttractorCriterion detects convergence to a known attractor,
+// |z - Attractor| < Epsilon.
+type AttractorCriterion struct {
+	Attractor complex128
+	Epsilon   float64
+}
+
+func (c AttractorCriterion) Check(z complex128, _ complex128, _ float64) Status {
+	if cmplx.Abs(z-c.Attractor) < c.Epsilon {
+		return Converged
+	}
+	return Iterating
+}
+
+// ConvergenceCriterion detects convergence to an unknown attractor by
+// comparing successive iterates, |z - zPrev| < Epsilon.
+type ConvergenceCriterion struct {
+	Epsilon float64
+}
+
+func (c ConvergenceCriterion) Check(z complex128, zPrev complex128, _ float64) Status {
+	if cmplx.Abs(z-zPrev) < c.Epsilon {
+		return Converged
+	}
+	return Iterating
+}
+
+// CombinedCriterion applies several criteria in turn. The first one to stop
+// the iteration decides the outcome.
+type CombinedCriterion []EscapeCriterion
+
+func (cc CombinedCriterion) Check(z complex128, zPrev complex128, radius float64) Status {
+	for _, c := range cc {
+		if status := c.Check(z, zPrev, radius); status != Iterating {
+			return status
+		}
+	}
+	return Iterating
+}
+
+func mod2(z complex128) float64 {
+	a, b := real(z), imag(z)
+	return a*a + b*b
+}
+
+// criterion returns the EscapeCriterion of f, or def if there is none.
+func (f *FracData) criterion(def EscapeCriterion) EscapeCriterion {
+	if f.Escape != nil {
+		return f.Escape
+	}
+	return def
+}
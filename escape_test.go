@@ -0,0 +1,73 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac_test
+
+import (
+	"github.com/cfdwalrus/gofrac"
+	"math/cmplx"
+	"testing"
+)
+
+func TestEscapeCriteria_Check(t *testing.T) {
+	r := 2.0
+	tc := []struct {
+		c     gofrac.EscapeCriterion
+		z     complex128
+		zPrev complex128
+		want  gofrac.Status
+	}{
+		{gofrac.ModulusCriterion{}, 1.5 + 1.5i, 0, gofrac.Escaped},
+		{gofrac.ModulusCriterion{}, 1.4 + 1.4i, 0, gofrac.Iterating},
+		{gofrac.ModulusCriterion{}, 0.1 + 2.1i, 0, gofrac.Escaped},
+		{gofrac.RealCriterion{}, 1.9 + 5i, 0, gofrac.Iterating},
+		{gofrac.RealCriterion{}, -2.1, 0, gofrac.Escaped},
+		{gofrac.ImagCriterion{}, 5 + 1.9i, 0, gofrac.Iterating},
+		{gofrac.ImagCriterion{}, -2.1i, 0, gofrac.Escaped},
+		{gofrac.ManhattanCriterion{}, 1.1 + 1.1i, 0, gofrac.Escaped},
+		{gofrac.ManhattanCriterion{}, 0.9 - 0.9i, 0, gofrac.Iterating},
+		{gofrac.MaxNormCriterion{}, 1.9 + 1.9i, 0, gofrac.Iterating},
+		{gofrac.MaxNormCriterion{}, 1.9 - 2.1i, 0, gofrac.Escaped},
+		{gofrac.AttractorCriterion{Attractor: 1, Epsilon: 0.01}, 1.005, 0, gofrac.Converged},
+		{gofrac.AttractorCriterion{Attractor: 1, Epsilon: 0.01}, 1.02, 0, gofrac.Iterating},
+		{gofrac.ConvergenceCriterion{Epsilon: 0.01}, 0.5, 0.505, gofrac.Converged},
+		{gofrac.ConvergenceCriterion{Epsilon: 0.01}, 0.5, 0.6, gofrac.Iterating},
+		{gofrac.ConvergenceCriterion{Epsilon: 0.01}, 0.5, cmplx.NaN(), gofrac.Iterating},
+		{gofrac.CombinedCriterion{gofrac.RealCriterion{}, gofrac.AttractorCriterion{Epsilon: 0.1}}, 3, 0, gofrac.Escaped},
+		{gofrac.CombinedCriterion{gofrac.RealCriterion{}, gofrac.AttractorCriterion{Epsilon: 0.1}}, 0.05, 0, gofrac.Converged},
+		{gofrac.CombinedCriterion{gofrac.RealCriterion{}, gofrac.AttractorCriterion{Epsilon: 0.1}}, 1, 0, gofrac.Iterating},
+	}
+
+	for _, tc := range tc {
+		if got := tc.c.Check(tc.z, tc.zPrev, r); got != tc.want {
+			t.Errorf("%T: z = %v, zPrev = %v: want: %v, got: %v", tc.c, tc.z, tc.zPrev, tc.want, got)
+		}
+	}
+}
+
+func TestFracData_Escape(t *testing.T) {
+	// 1.5 + 1.5i is within the max-norm square but outside the circle of
+	// radius 2, so the two criteria disagree about it
+	c := 1.5 + 1.5i
+	j := gofrac.NewJuliaQ(2, 0)
+	j.SetMaxIterations(10)
+
+	if got := j.Frac(c); got.Iterations != 0 {
+		t.Errorf("%T: default criterion: want: 0 iterations, got: %d", j, got.Iterations)
+	}
+
+	j.SetEscapeCriterion(gofrac.MaxNormCriterion{})
+	if got := j.Frac(c); got.Iterations != 1 {
+		t.Errorf("%T: %T: want: 1 iteration, got: %d", j, j.Escape, got.Iterations)
+	}
+
+	// the iterate of 0.5 under z^2 converges to 0
+	j.SetEscapeCriterion(gofrac.CombinedCriterion{
+		gofrac.ModulusCriterion{},
+		gofrac.AttractorCriterion{Epsilon: 1e-3},
+	})
+	if got := j.Frac(0.5); !got.Converged || got.Iterations >= 9 {
+		t.Errorf("%T: want: convergence, got: %v", j, *got)
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"math"
+	"math/cmplx"
+)
+
+// ExpMapDomain samples the complex plane in log-polar coordinates around a
+// center point: the angle about the center increases along the x axis, and
+// the logarithm of the distance from the center decreases along the y axis.
+// Its samples are square, so one row covers a full revolution and the
+// distance shrinks by a factor of exp(2*pi/cols) from one row to the next.
+//
+// A single image of an ExpMapDomain, the exponential map, contains every
+// frame of a zoom into its center, and ExpMapFrame reprojects it into them.
+// This is far cheaper than rendering each frame separately.
+type ExpMapDomain struct {
+	center complex128
+	logMax float64
+	xs, ys int
+
+	// step is the angle and the log-distance between neighboring samples
+	step float64
+}
+
+// NewExpMapDomain constructs an ExpMapDomain around center whose first row
+// lies at the distance rMax from it. Each row is sampled xSamples times
+// around the center, and ySamples rows are taken, reaching down to a distance
+// of rMax * exp(-2*pi*ySamples/xSamples).
+func NewExpMapDomain(center complex128, rMax float64, xSamples, ySamples int) (*ExpMapDomain, error) {
+	if xSamples <= 0 || ySamples <= 0 {
+		return nil, errors.New("gofrac: The number of samples along any axis must be greater than zero")
+	}
+	if rMax <= 0 {
+		return nil, errors.New("gofrac: the extent of a domain must be greater than zero")
+	}
+
+	return &ExpMapDomain{
+		center: center,
+		logMax: math.Log(rMax),
+		xs:     xSamples,
+		ys:     ySamples,
+		step:   2 * math.Pi / float64(xSamples),
+	}, nil
+}
+
+func (d *ExpMapDomain) At(i int, j int) (loc complex128, err error) {
+	if i < 0 || i >= d.xs || j < 0 || j >= d.ys {
+		return 0, errors.New("gofrac: sample is out of bounds")
+	}
+	return d.point(float64(i), float64(j)), nil
+}
+
+// point maps the fractional sample coordinates (i, j) onto the plane.
+func (d *ExpMapDomain) point(i float64, j float64) complex128 {
+	return d.center + cmplx.Rect(math.Exp(d.logMax-j*d.step), i*d.step)
+}
+
+// coords returns the fractional sample coordinates of loc, with the x
+// coordinate in [0, cols).
+func (d *ExpMapDomain) coords(loc complex128) (x float64, y float64) {
+	delta := loc - d.center
+	theta := cmplx.Phase(delta)
+	if theta < 0 {
+		theta += 2 * math.Pi
+	}
+	x = math.Mod(theta/d.step, float64(d.xs))
+	y = (d.logMax - math.Log(cmplx.Abs(delta))) / d.step
+	return x, y
+}
+
+func (d *ExpMapDomain) Dimensions() (rows int, cols int) {
+	return d.ys, d.xs
+}
+
+func (d *ExpMapDomain) PixelOf(loc complex128) (i int, j int, ok bool) {
+	x, y := d.coords(loc)
+	return cellOf(x, y, d.xs, d.ys)
+}
+
+// PixelBounds returns the corners of the cell of the sample (i, j). The
+// edges of a cell are arcs and rays, which the corners only approximate.
+func (d *ExpMapDomain) PixelBounds(i int, j int) (corners [4]complex128, err error) {
+	if i < 0 || i >= d.xs || j < 0 || j >= d.ys {
+		return corners, errors.New("gofrac: sample is out of bounds")
+	}
+	return cellCorners(i, j, d.point), nil
+}
+
+// Center returns the center of the domain.
+func (d *ExpMapDomain) Center() complex128 {
+	return d.center
+}
+
+// Radii returns the distances of the first and last rows of the domain from
+// its center.
+func (d *ExpMapDomain) Radii() (rMax float64, rMin float64) {
+	return math.Exp(d.logMax), math.Exp(d.logMax - float64(d.ys-1)*d.step)
+}
+
+// ExpMapFrame reprojects strip, an image of the ExpMapDomain d such as
+// GetImage returns, into the frame of a zoom into the center of d that is
+// width wide and has w by h pixels. Pixels are interpolated bilinearly
+// between the samples of the strip. Parts of the frame that the strip doesn't
+// cover, i.e. those farther from the center than the first row or closer than
+// the last, are left transparent, so the frame is only complete if its
+// corners lie within the first row.
+func ExpMapFrame(strip image.Image, d *ExpMapDomain, width float64, w, h int) (*image.RGBA, error) {
+	b := strip.Bounds()
+	if rows, cols := d.Dimensions(); b.Dx() != cols || b.Dy() != rows {
+		return nil, errors.New("gofrac: the strip doesn't match the dimensions of the domain")
+	}
+	frame, err := NewView(d.center, width, 0, w, h)
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			loc, _ := frame.At(i, j)
+			if loc == d.center {
+				continue
+			}
+			x, y := d.coords(loc)
+			if y < 0 || y > float64(d.ys-1) {
+				continue
+			}
+			img.Set(i, j, bilinear(strip, x, y, d.xs))
+		}
+	}
+	return img, nil
+}
+
+// ExpMapFrames reprojects strip into n frames of a zoom into the center of the
+// ExpMapDomain d, as ExpMapFrame does. The first frame is width wide, and each
+// following one is magnified by factor relative to the one before it. Each
+// frame is passed to fn as soon as it's done, along with its index. If fn
+// returns an error, no further frames are produced and the error is returned.
+// An error is also returned if factor isn't greater than zero.
+func ExpMapFrames(strip image.Image, d *ExpMapDomain, width float64, factor float64, n int, w, h int, fn func(k int, frame *image.RGBA) error) error {
+	if !(factor > 0) {
+		return errors.New("gofrac: the zoom factor must be greater than zero")
+	}
+	for k := 0; k < n; k++ {
+		frame, err := ExpMapFrame(strip, d, width, w, h)
+		if err != nil {
+			return err
+		}
+		if err := fn(k, frame); err != nil {
+			return err
+		}
+		width /= factor
+	}
+	return nil
+}
+
+// bilinear interpolates the color of img at the fractional pixel coordinates
+// (x, y), relative to its top-left corner. The x coordinate wraps around
+// after cols pixels.
+func bilinear(img image.Image, x float64, y float64, cols int) color.Color {
+	b := img.Bounds()
+	x0, y0 := math.Floor(x), math.Floor(y)
+	tx, ty := x-x0, y-y0
+	i0, j0 := int(x0)%cols, int(y0)
+	i1, j1 := (i0+1)%cols, j0+1
+	if j1 >= b.Dy() {
+		j1 = j0
+	}
+
+	at := func(i, j int) (r, g, bl, a float64) {
+		cr, cg, cb, ca := img.At(b.Min.X+i, b.Min.Y+j).RGBA()
+		return float64(cr), float64(cg), float64(cb), float64(ca)
+	}
+	weights := [4]float64{(1 - tx) * (1 - ty), tx * (1 - ty), (1 - tx) * ty, tx * ty}
+	corners := [4][2]int{{i0, j0}, {i1, j0}, {i0, j1}, {i1, j1}}
+	var r, g, bl, a float64
+	for k, c := range corners {
+		cr, cg, cb, ca := at(c[0], c[1])
+		r += weights[k] * cr
+		g += weights[k] * cg
+		bl += weights[k] * cb
+		a += weights[k] * ca
+	}
+	return color.RGBA64{
+		R: uint16(math.Round(r)),
+		G: uint16(math.Round(g)),
+		B: uint16(math.Round(bl)),
+		A: uint16(math.Round(a)),
+	}
+}
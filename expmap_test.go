@@ -0,0 +1,97 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac_test
+
+import (
+	"errors"
+	"github.com/cfdwalrus/gofrac"
+	"image"
+	"image/color"
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestNewExpMapDomain(t *testing.T) {
+	if _, err := gofrac.NewExpMapDomain(0, 1, 0, 10); err == nil {
+		t.Errorf("Error not caught for zero samples")
+	}
+	if _, err := gofrac.NewExpMapDomain(0, 0, 10, 10); err == nil {
+		t.Errorf("Error not caught for zero radius")
+	}
+
+	c := -0.75 + 0.1i
+	d, _ := gofrac.NewExpMapDomain(c, 2, 64, 100)
+	if rows, cols := d.Dimensions(); rows != 100 || cols != 64 {
+		t.Errorf("%T: want: (100, 64), got: (%d, %d)", d, rows, cols)
+	}
+
+	// a quarter turn along each row, and a factor of exp(2pi) every 64 rows
+	loc, _ := d.At(16, 64)
+	want := c + complex(0, 2*math.Exp(-2*math.Pi))
+	if cmplx.Abs(loc-want) > 1e-12 {
+		t.Errorf("%T: At(16, 64): want: %v, got: %v", d, want, loc)
+	}
+	if rMax, rMin := d.Radii(); math.Abs(rMax-2) > 1e-12 || math.Abs(rMin-2*math.Exp(-2*math.Pi*99/64)) > 1e-12 {
+		t.Errorf("%T: want: radii (2, %g), got: (%g, %g)", d, 2*math.Exp(-2*math.Pi*99/64), rMax, rMin)
+	}
+	if _, err := d.At(64, 0); err == nil {
+		t.Errorf("%T: Error not caught for out of bounds sample", d)
+	}
+
+	checkInvertible(t, d)
+}
+
+func TestExpMapFrame(t *testing.T) {
+	const xs, ys = 64, 80
+	d, _ := gofrac.NewExpMapDomain(0.25, 1, xs, ys)
+
+	// the strip's gray level falls off with the log-distance from the center
+	strip := image.NewRGBA(image.Rect(0, 0, xs, ys))
+	for j := 0; j < ys; j++ {
+		for i := 0; i < xs; i++ {
+			strip.Set(i, j, color.Gray{Y: uint8(255 - 3*j)})
+		}
+	}
+
+	const w, h = 40, 30
+	width := 1.5
+	err := gofrac.ExpMapFrames(strip, d, width, 2, 3, w, h, func(k int, frame *image.RGBA) error {
+		if k > 0 {
+			return errors.New("stop")
+		}
+		v, _ := gofrac.NewView(0.25, width, 0, w, h)
+		for j := 0; j < h; j++ {
+			for i := 0; i < w; i++ {
+				loc, _ := v.At(i, j)
+				y := -math.Log(cmplx.Abs(loc-0.25)) * xs / (2 * math.Pi)
+				got := frame.RGBAAt(i, j)
+				if y > ys-1 {
+					if got.A != 0 {
+						t.Errorf("%T: (%d, %d): want: transparent, got: %v", d, i, j, got)
+					}
+					continue
+				}
+				if want := 255 - 3*y; math.Abs(float64(got.R)-want) > 1 || got.A != 255 {
+					t.Errorf("%T: (%d, %d): want: gray %.1f, got: %v", d, i, j, want, got)
+				}
+			}
+		}
+		return nil
+	})
+	if err == nil || err.Error() != "stop" {
+		t.Errorf("ExpMapFrames: want: error from callback, got: %v", err)
+	}
+
+	noop := func(int, *image.RGBA) error { return nil }
+	for _, factor := range []float64{0, -2} {
+		if err := gofrac.ExpMapFrames(strip, d, width, factor, 3, w, h, noop); err == nil {
+			t.Errorf("Error not caught for zoom factor %v", factor)
+		}
+	}
+	if _, err := gofrac.ExpMapFrame(image.NewRGBA(image.Rect(0, 0, 10, 10)), d, width, w, h); err == nil {
+		t.Errorf("Error not caught for mismatched strip")
+	}
+}
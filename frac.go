@@ -5,11 +5,11 @@
 package gofrac
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"math"
 	"math/cmplx"
-	"runtime"
-	"sync"
 )
 
 //var debug = log.New(os.Stdout, "DEBUG: ", log.LstdFlags)
@@ -31,12 +31,41 @@ type FracDataGetter interface {
 // domain d. The maximum number of iterations to be performed is given by
 // iterations.
 func FracIt(d DomainReader, f Fraccer, iterations int) (*Results, error) {
+	return FracItContext(context.Background(), d, f, iterations)
+}
+
+// FracItContext is like FracIt, but stops its workers as soon as ctx is
+// cancelled. In that case, the partially computed Results are returned along
+// with ctx.Err(). Samples that were never reached are left zeroed.
+//
+// If the domain fails to produce a sample, the calculation is stopped in the
+// same way and a *SampleError describing the failure is returned.
+func FracItContext(ctx context.Context, d DomainReader, f Fraccer, iterations int) (*Results, error) {
+	return FracItOptions(ctx, d, f, iterations, nil)
+}
+
+// FracItOptions is like FracItContext, but its behavior can be further
+// configured with opts, which may be nil.
+func FracItOptions(ctx context.Context, d DomainReader, f Fraccer, iterations int, opts *Options) (*Results, error) {
 	err := f.SetMaxIterations(iterations)
 	if err != nil {
 		return nil, err
 	}
 
 	rows, cols := d.Dimensions()
+	return fracIt(ctx, rows, cols, iterations, opts, func(row, col int) (*Result, error) {
+		loc, err := d.At(col, row)
+		if err != nil {
+			return nil, err
+		}
+		return f.Frac(loc), nil
+	})
+}
+
+// fracIt calls frac for every sample of a domain of rows by cols samples and
+// collects the Results. It implements the behavior shared by FracItOptions
+// and FracItBig.
+func fracIt(ctx context.Context, rows int, cols int, iterations int, opts *Options, frac func(row, col int) (*Result, error)) (*Results, error) {
 	if cols < 1 || rows < 1 {
 		return nil, errors.New("gofrac: the domain must be sampled at least once along each axis")
 	}
@@ -44,35 +73,39 @@ func FracIt(d DomainReader, f Fraccer, iterations int) (*Results, error) {
 	results := NewResults(rows, cols, iterations)
 	defer results.Done()
 
-	rowJobs := make(chan int, rows)
-
-	numWorkers := runtime.NumCPU()
-	wg := sync.WaitGroup{}
-	for worker := 0; worker < numWorkers; worker++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for row := range rowJobs {
-				for col := 0; col < cols; col++ {
-					loc, err := d.At(col, row)
-					if err != nil {
-						panic(err)
-					}
-					r := f.Frac(loc)
-					results.SetResult(row, col, r.Z, r.C, r.Iterations)
-				}
-			}
-		}()
+	progress := newProgressTracker(opts, StageFrac, rows, cols)
+	if opts.subdivide() {
+		err := subdivide(ctx, rows, cols, opts, progress, results, frac)
+		return &results, err
 	}
 
-	for row := 0; row < rows; row++ {
-		rowJobs <- row
-	}
+	err := forEachJob(ctx, rows, cols, opts, progress, func(row, col0, col1 int) error {
+		for col := col0; col < col1; col++ {
+			r, err := frac(row, col)
+			if err != nil {
+				return &SampleError{Row: row, Col: col, Err: err}
+			}
+			results.set(row, col, r)
+		}
+		return nil
+	})
+
+	return &results, err
+}
+
+// SampleError records a failure to read a sample from a DomainReader.
+type SampleError struct {
+	// Row and Col are the coordinates of the offending sample.
+	Row, Col int
+	Err      error
+}
 
-	close(rowJobs)
-	wg.Wait()
+func (e *SampleError) Error() string {
+	return fmt.Sprintf("gofrac: sample (row %d, col %d): %v", e.Row, e.Col, e.Err)
+}
 
-	return &results, nil
+func (e *SampleError) Unwrap() error {
+	return e.Err
 }
 
 type FracData struct {
@@ -83,6 +116,46 @@ type FracData struct {
 	// exceeded Radius.
 	MaxIterations int
 
+	// Escape decides when iteration stops. If it is nil, each fractal uses
+	// its own default, which is usually ModulusCriterion.
+	Escape EscapeCriterion
+
+	// Epsilon is the convergence threshold of fractals that have a finite
+	// attractor in addition to (or instead of) escaping to infinity.
+	Epsilon float64
+
+	// Derivative enables tracking of the derivative of the iterates, which is
+	// stored in Result.DZ. It is supported by the holomorphic escape-time
+	// fractals: Mandelbrot, JuliaQ, Multibrot, MultiJulia and DeepMandelbrot.
+	Derivative bool
+
+	// Traps are the orbit traps against which every iterate is measured.
+	Traps []OrbitTrap
+
+	// Average computes the terms of an average taken over every orbit, for
+	// the average-sum coloring methods.
+	Average Averager
+
+	// Cycles enables the detection of the attracting cycles of orbits that
+	// don't escape. It is supported by Mandelbrot, JuliaQ, Multibrot and
+	// MultiJulia. DeepMandelbrot doesn't detect cycles, so its Results never
+	// have a Period.
+	Cycles bool
+
+	// Periodicity enables periodicity checking, which stops iterating an
+	// orbit as soon as it returns to within CycleTolerance of an earlier
+	// iterate, and reports it as bounded. This saves the bulk of the work for
+	// points inside the set, but the reported Z is no longer the last of
+	// MaxIterations iterates. It is supported by Mandelbrot, JuliaQ,
+	// Multibrot and MultiJulia, but not by DeepMandelbrot, and is ignored if
+	// orbit traps or an Averager need the full orbit.
+	Periodicity bool
+
+	// CycleTolerance is the distance within which two iterates are
+	// considered to be the same point of a cycle, for both cycle detection
+	// and periodicity checking. If it is zero, 1e-10 is used.
+	CycleTolerance float64
+
 	// degree is the degree of the complex polynomial function to be iterated.
 	degree float64
 
@@ -106,38 +179,165 @@ func (f *FracData) SetMaxIterations(n int) error {
 	return nil
 }
 
+func (f *FracData) SetEscapeCriterion(c EscapeCriterion) {
+	f.Escape = c
+}
+
+func (f *FracData) SetEpsilon(eps float64) {
+	f.Epsilon = eps
+}
+
+func (f *FracData) SetDerivative(on bool) {
+	f.Derivative = on
+}
+
+func (f *FracData) SetTraps(traps ...OrbitTrap) {
+	f.Traps = traps
+}
+
+func (f *FracData) SetAverager(a Averager) {
+	f.Average = a
+}
+
+func (f *FracData) SetCycles(on bool) {
+	f.Cycles = on
+}
+
+func (f *FracData) SetPeriodicity(on bool) {
+	f.Periodicity = on
+}
+
 func (f *FracData) SetDegree(d float64) {
 	f.degree = d
 	f.logDegreeInv = 1 / math.Log(d)
 }
 
+// iterate applies z_{n+1} = v(z_n) + c, starting from z, until the iterate
+// escapes or MaxIterations is reached. For an ordinary quadratic fractal, v is
+// simply z^2. Escape is judged by the EscapeCriterion of f, which defaults to
+// the modulus test.
+func (f *FracData) iterate(z complex128, c complex128, v CCMap) *Result {
+	return f.iterateDZ(z, c, v, nil, 0, 0)
+}
+
+// iterateDZ is like iterate, but if f.Derivative is set and dv, the derivative
+// of v, is given, it also tracks the derivative of the iterates with respect
+// to the plotted variable: dz_{n+1} = dv(z_n) * dz_n + dc, starting from dz.
+// For the parameter plane (e.g., Mandelbrot), the variable is c, so dz starts
+// at dz_0/dc and dc is 1. For the dynamical plane (e.g., JuliaQ), it is z_0,
+// so dz starts at 1 and dc is 0.
+func (f *FracData) iterateDZ(z complex128, c complex128, v CCMap, dv CCMap, dz complex128, dc complex128) *Result {
+	crit := f.criterion(ModulusCriterion{})
+	count := 0
+	maxIt := f.MaxIterations - 1
+	track := f.Derivative && dv != nil
+	if !track {
+		dz = 0
+	}
+	o := f.newOrbit(z, c)
+
+	// periodicity checking compares the iterates with one saved at
+	// power-of-two intervals, as in Brent's cycle detection
+	periodic := f.Periodicity && len(f.Traps) == 0 && f.Average == nil
+	tol2 := f.cycleTolerance() * f.cycleTolerance()
+	saved, lam, power := z, 0, 1
+
+	// there's no predecessor to compare the first iterate to
+	zPrev := cmplx.NaN()
+	status := crit.Check(z, zPrev, f.Radius)
+	for status == Iterating {
+		if track {
+			dz = dv(z)*dz + dc
+		}
+		z, zPrev = v(z)+c, z
+		o.visit(z, count+1)
+		if count == maxIt {
+			break
+		}
+		count++
+		status = crit.Check(z, zPrev, f.Radius)
+
+		if periodic && status == Iterating {
+			if mod2(z-saved) < tol2 {
+				count = maxIt
+				break
+			}
+			lam++
+			if lam == power {
+				saved, lam, power = z, 0, 2*power
+			}
+		}
+	}
+	r := &Result{
+		Z:          z,
+		C:          c,
+		Iterations: count,
+		Converged:  status == Converged,
+		DZ:         dz,
+	}
+	// the loop stops short of checking the last iterate, which may have
+	// escaped, too
+	if f.Cycles && status == Iterating && crit.Check(z, zPrev, f.Radius) == Iterating {
+		s := r.stats()
+		s.Period, s.Multiplier = f.cycle(z, c, v, dv)
+	}
+	return o.done(r)
+}
+
 // Quadratic stores the information needed by a quadratic fractal.
 type Quadratic struct {
 	FracData
 }
 
-func getMod2(z complex128) float64 {
-	a, b := real(z), imag(z)
-	return a*a + b+b
+func square(z complex128) complex128 {
+	return z * z
+}
+
+func dSquare(z complex128) complex128 {
+	return 2 * z
+}
+
+func (q Quadratic) q(z complex128, c complex128, dz complex128, dc complex128) *Result {
+	if q.plain() {
+		return q.escapeTime(z, c)
+	}
+	return q.iterateDZ(z, c, square, dSquare, dz, dc)
 }
 
-func (q Quadratic) q(z complex128, c complex128) *Result {
+// plain reports whether none of the optional features of f are enabled, so
+// that only the escape time of an orbit is of interest.
+func (f *FracData) plain() bool {
+	return f.Escape == nil && !f.Derivative && !f.Periodicity && !f.needsOrbit()
+}
+
+// escapeTime iterates z_{n+1} = z_n^2 + c until the iterate leaves the
+// bailout radius. It gives the same Results as iterateDZ with the default
+// ModulusCriterion and no optional features, but its loop is tight enough to
+// run several times as fast.
+func (f *FracData) escapeTime(z complex128, c complex128) *Result {
+	r2 := f.Radius * f.Radius
+	maxIt := f.MaxIterations - 1
+	x, y := real(z), imag(z)
+	cr, ci := real(c), imag(c)
+	x2, y2 := x*x, y*y
+	if x2+y2 > r2 {
+		return &Result{Z: z, C: c}
+	}
+
 	count := 0
-	r2 := q.Radius * q.Radius
-	maxIt := q.MaxIterations-1
-	for mod2 := getMod2(z); mod2 <= r2; mod2 = getMod2(z) {
-		z = z*z + c
+	for {
+		y = 2*x*y + ci
+		x = x2 - y2 + cr
+		x2, y2 = x*x, y*y
 		if count == maxIt {
 			break
 		}
-		mod2 = getMod2(z)
 		count++
+		if x2+y2 > r2 {
+			break
+		}
 	}
-	return &Result{
-		Z:          z,
-		C:          c,
-		Iterations: count,
-	}
+	return &Result{Z: complex(x, y), C: c, Iterations: count}
 }
 
 // The Mandelbrot set, which results from iterating the function
@@ -176,6 +376,10 @@ func isCardioidOrP2Bulb(z complex128, maxIt int) bool {
 	// q = \left(\Re(z) - \frac{1}{4}\right)^2 + \Im(z)^2
 	// q \left( q \left( \Re(z) - \frac{1}{4} \right ) \right ) \leq \frac{\Im(z)^2}{4}
 	q := rzp*rzp + imz2
+	if math.IsInf(q, 0) {
+		// the point at infinity passes the test, but escapes at once
+		return false
+	}
 	isCardiod := q*(q+rzp) <= 0.25*imz2
 
 	if isCardiod {
@@ -190,14 +394,20 @@ func isCardioidOrP2Bulb(z complex128, maxIt int) bool {
 }
 
 func (m Mandelbrot) Frac(loc complex128) *Result {
-	if isCardioidOrP2Bulb(loc, m.MaxIterations) {
+	// the orbits of points inside the set are only skipped if nothing but
+	// their escape time is of interest
+	if !m.needsOrbit() && isCardioidOrP2Bulb(loc, m.MaxIterations) {
 		return &Result{
 			Z:          loc,
 			C:          0,
 			Iterations: m.MaxIterations - 1,
 		}
 	}
-	return m.q(0, loc)
+	r := m.q(0, loc, 0, 1)
+	if s := r.Stats; s != nil && s.Period > 0 {
+		s.InteriorDistance = interiorDistance(r.Z, loc, s.Period)
+	}
+	return r
 }
 
 // JuliaQ is the quadratic Julia set, which results from iterating the function
@@ -217,7 +427,7 @@ func NewJuliaQ(radius float64, c complex128) *JuliaQ {
 }
 
 func (j JuliaQ) Frac(loc complex128) *Result {
-	return j.q(loc, j.C)
+	return j.q(loc, j.C, 1, 0)
 }
 
 // JuliaR is a Julia set generated by a rational complex function given by
@@ -230,19 +440,9 @@ type JuliaR struct {
 }
 
 func (r JuliaR) Frac(loc complex128) *Result {
-	z := loc
-	count := 0
-	for mod := cmplx.Abs(z); mod <= r.Radius; mod, count = cmplx.Abs(z), count+1 {
-		z = r.P(z)/r.Q(z) + r.C
-		if count == r.MaxIterations-1 {
-			break
-		}
-	}
-	return &Result{
-		Z:          z,
-		C:          r.C,
-		Iterations: count,
-	}
+	return r.iterate(loc, r.C, func(z complex128) complex128 {
+		return r.P(z) / r.Q(z)
+	})
 }
 
 // Polynomiograph contains the data necessary to perform the style of
@@ -290,9 +490,14 @@ func (m MandelPG) Frac(loc complex128) *Result {
 	z := loc
 	c := m.F(loc)
 	count := 0
+	converged := false
+	crit := m.criterion(ConvergenceCriterion{Epsilon: m.eps})
+	o := m.newOrbit(z, -c)
 	for {
 		zNext := m.B(z) - c
-		if cmplx.Abs(zNext - z) < m.eps {
+		o.visit(zNext, count+1)
+		if status := crit.Check(zNext, z, m.Radius); status != Iterating {
+			converged = status == Converged
 			break
 		}
 		c = m.G(c)
@@ -303,10 +508,11 @@ func (m MandelPG) Frac(loc complex128) *Result {
 		count++
 	}
 
-	return &Result{
+	return o.done(&Result{
 		Z:          z,
 		C:          c,
 		Iterations: count,
 		NFactor:    0,
-	}
+		Converged:  converged,
+	})
 }
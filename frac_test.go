@@ -5,7 +5,11 @@
 package gofrac_test
 
 import (
+	"context"
+	"errors"
 	"github.com/cfdwalrus/gofrac"
+	"math/cmplx"
+	"sync/atomic"
 	"testing"
 )
 
@@ -123,3 +127,279 @@ func TestFracIt(t *testing.T) {
 		}
 	}
 }
+
+func TestFracItContext(t *testing.T) {
+	dimensionsMock = func() (int, int) {
+		return 10, 10
+	}
+
+	// an already cancelled context stops FracItContext before any work is
+	// done, but still yields the Results
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var calls int64
+	mockFrac = func(complex128) *gofrac.Result {
+		atomic.AddInt64(&calls, 1)
+		return &gofrac.Result{Iterations: 1}
+	}
+	r, err := gofrac.FracItContext(ctx, fakeDomain{}, &fakeFrac{}, 10)
+	if err != context.Canceled {
+		t.Errorf("FracItContext: want: err == %v, got: err == %v", context.Canceled, err)
+	}
+	if r == nil {
+		t.Fatalf("FracItContext: want: partial Results, got: nil")
+	}
+	if calls != 0 {
+		t.Errorf("FracItContext: want: 0 calls to Frac, got: %d", calls)
+	}
+
+	// a live context behaves like FracIt
+	r, err = gofrac.FracItContext(context.Background(), fakeDomain{}, &fakeFrac{}, 10)
+	if err != nil {
+		t.Error(err)
+	}
+	if rows, cols := r.Dimensions(); rows != 10 || cols != 10 {
+		t.Errorf("%T: want: rows = 10, cols = 10, got: rows = %d, cols = %d", r, rows, cols)
+	}
+}
+
+func TestFracItContext_CancelMidway(t *testing.T) {
+	const rows, cols = 200, 200
+	dimensionsMock = func() (int, int) {
+		return rows, cols
+	}
+
+	// the context is cancelled once a few rows are done, which stops the
+	// workers before they reach the rest
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var calls int64
+	mockFrac = func(complex128) *gofrac.Result {
+		if atomic.AddInt64(&calls, 1) == 5*cols {
+			cancel()
+		}
+		return &gofrac.Result{Iterations: 1}
+	}
+	r, err := gofrac.FracItContext(ctx, fakeDomain{}, &fakeFrac{}, 10)
+	if err != context.Canceled {
+		t.Errorf("FracItContext: want: err == %v, got: err == %v", context.Canceled, err)
+	}
+	if r == nil {
+		t.Fatalf("FracItContext: want: partial Results, got: nil")
+	}
+	if n := atomic.LoadInt64(&calls); n >= rows*cols {
+		t.Errorf("FracItContext: want: fewer than %d calls to Frac, got: %d", rows*cols, n)
+	}
+}
+
+func TestFracItOptions_Progress(t *testing.T) {
+	rows := 10
+	dimensionsMock = func() (int, int) {
+		return rows, 3
+	}
+	mockFrac = func(complex128) *gofrac.Result {
+		return &gofrac.Result{}
+	}
+
+	var reports []gofrac.Progress
+	opts := &gofrac.Options{
+		Progress: func(p gofrac.Progress) {
+			reports = append(reports, p)
+		},
+	}
+	_, err := gofrac.FracItOptions(context.Background(), fakeDomain{}, &fakeFrac{}, 10, opts)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if len(reports) != rows {
+		t.Fatalf("FracItOptions: want: %d progress reports, got: %d", rows, len(reports))
+	}
+	for i, p := range reports {
+		if p.Stage != gofrac.StageFrac || p.Completed != i+1 || p.Total != rows {
+			t.Errorf("%T: want: {%v %d/%d}, got: {%v %d/%d}", p, gofrac.StageFrac, i+1, rows, p.Stage, p.Completed, p.Total)
+		}
+	}
+	if last := reports[len(reports)-1]; last.ETA != 0 || last.Fraction() != 1 {
+		t.Errorf("%T: want: ETA = 0, Fraction = 1, got: ETA = %v, Fraction = %0.2f", last, last.ETA, last.Fraction())
+	}
+}
+
+type failingDomain struct {
+	fakeDomain
+	row, col int
+}
+
+var errBadSample = errors.New("bad sample")
+
+func (d failingDomain) At(i int, j int) (complex128, error) {
+	if i == d.col && j == d.row {
+		return 0, errBadSample
+	}
+	return 0, nil
+}
+
+func TestFracIt_SampleError(t *testing.T) {
+	dimensionsMock = func() (int, int) {
+		return 10, 10
+	}
+	mockFrac = func(complex128) *gofrac.Result {
+		return &gofrac.Result{}
+	}
+
+	d := failingDomain{row: 7, col: 3}
+	_, err := gofrac.FracIt(d, &fakeFrac{}, 10)
+
+	var sampleErr *gofrac.SampleError
+	if !errors.As(err, &sampleErr) {
+		t.Fatalf("FracIt: want: *gofrac.SampleError, got: %v", err)
+	}
+	if sampleErr.Row != d.row || sampleErr.Col != d.col {
+		t.Errorf("%T: want: (row, col) = (%d, %d), got: (%d, %d)", sampleErr, d.row, d.col, sampleErr.Row, sampleErr.Col)
+	}
+	if !errors.Is(err, errBadSample) {
+		t.Errorf("%T: want: wrapped %v, got: %v", sampleErr, errBadSample, sampleErr.Err)
+	}
+}
+
+// checkDerivative compares the derivative tracked by f at loc with a finite
+// difference approximation.
+func checkDerivative(t *testing.T, f gofrac.Fraccer, loc complex128) {
+	t.Helper()
+	const h = 1e-7
+	f.Data().SetDerivative(true)
+	r0, r1 := f.Frac(loc), f.Frac(loc+h)
+	want := (r1.Z - r0.Z) / h
+	if got := r0.DZ; cmplx.Abs(got-want) > 1e-4*cmplx.Abs(want) {
+		t.Errorf("%T: loc = %v: want: %v, got: %v", f, loc, want, got)
+	}
+}
+
+func TestMandelbrot_Derivative(t *testing.T) {
+	m := gofrac.NewMandelbrot(1e10)
+	m.SetMaxIterations(6)
+	for _, c := range []complex128{-0.75 + 0.3i, 0.3 + 0.5i, -1.3 + 0.05i} {
+		checkDerivative(t, m, c)
+	}
+
+	// without tracking, no derivative is reported
+	m.SetDerivative(false)
+	if got := m.Frac(0.3 + 0.5i).DZ; got != 0 {
+		t.Errorf("%T: want: %v, got: %v", m, 0, got)
+	}
+}
+
+func TestJuliaQ_Derivative(t *testing.T) {
+	j := gofrac.NewJuliaQ(1e10, -0.8+0.156i)
+	j.SetMaxIterations(6)
+	for _, z := range []complex128{0.1 + 0.2i, -0.5 + 0.4i, 0.9 - 0.1i} {
+		checkDerivative(t, j, z)
+	}
+}
+
+func TestMandelbrot_Periodicity(t *testing.T) {
+	// periodicity checking changes nothing but the time it takes. (The view
+	// avoids c = i, which is preperiodic, but escapes after a few dozen
+	// iterations due to rounding errors when periodicity isn't checked.)
+	d, _ := gofrac.NewDomain(-2, -1.2, 0.6, 1.2, 53, 49)
+	plain := gofrac.NewMandelbrot(2)
+	want, err := gofrac.FracIt(d, plain, 500)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := gofrac.NewMandelbrot(2)
+	m.SetPeriodicity(true)
+	got, err := gofrac.FracIt(d, m, 500)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for row := 0; row < 49; row++ {
+		for col := 0; col < 53; col++ {
+			w, g := want.At(row, col).Iterations, got.At(row, col).Iterations
+			if w != g {
+				t.Errorf("%T: (row, col) = (%d, %d): want: %d, got: %d", m, row, col, w, g)
+			}
+		}
+	}
+}
+
+// benchmarkPeriodicity times the calculation of an interior-heavy view of f,
+// with and without periodicity checking.
+func benchmarkPeriodicity(b *testing.B, f gofrac.Fraccer, d gofrac.DomainReader) {
+	for _, on := range []bool{false, true} {
+		name := "off"
+		if on {
+			name = "on"
+		}
+		b.Run(name, func(b *testing.B) {
+			f.Data().SetPeriodicity(on)
+			for i := 0; i < b.N; i++ {
+				if _, err := gofrac.FracIt(d, f, 5000); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkMandelbrot_Periodicity(b *testing.B) {
+	// the period 3 bulb, which the cardioid and period 2 bulb tests miss
+	d, _ := gofrac.NewDomain(-0.3, 0.6, 0.1, 0.9, 64, 48)
+	benchmarkPeriodicity(b, gofrac.NewMandelbrot(2), d)
+}
+
+func BenchmarkJuliaQ_Periodicity(b *testing.B) {
+	// the Douady rabbit, whose interior is attracted to a 3-cycle
+	d, _ := gofrac.NewDomain(-1.5, -1, 1.5, 1, 64, 48)
+	benchmarkPeriodicity(b, gofrac.NewJuliaQ(2, -0.12+0.75i), d)
+}
+
+// benchmarkPlain times the calculation of a 400x300 view of f on a single
+// worker, with none of the optional features of FracData enabled.
+func benchmarkPlain(b *testing.B, f gofrac.Fraccer, d gofrac.DomainReader) {
+	opts := &gofrac.Options{Workers: 1}
+	for i := 0; i < b.N; i++ {
+		if _, err := gofrac.FracItOptions(context.Background(), d, f, 1000, opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMandelbrot(b *testing.B) {
+	d, _ := gofrac.NewDomain(-2, -1.2, 0.6, 1.2, 400, 300)
+	benchmarkPlain(b, gofrac.NewMandelbrot(2), d)
+}
+
+func BenchmarkJuliaQ(b *testing.B) {
+	d, _ := gofrac.NewDomain(-1.5, -1, 1.5, 1, 400, 300)
+	benchmarkPlain(b, gofrac.NewJuliaQ(2, -0.12+0.75i), d)
+}
+
+func TestQuadratic_Plain(t *testing.T) {
+	// setting the default criterion explicitly takes the general path,
+	// which the plain escape time loop must agree with
+	md, _ := gofrac.NewDomain(-2.2, -1.2, 0.6, 1.2, 70, 60)
+	jd, _ := gofrac.NewDomain(-1.5, -1, 1.5, 1, 60, 40)
+	fracs := []struct {
+		plain, general gofrac.Fraccer
+		d              gofrac.DomainReader
+	}{
+		{gofrac.NewMandelbrot(2), gofrac.NewMandelbrot(2), md},
+		{gofrac.NewJuliaQ(2, -0.12+0.75i), gofrac.NewJuliaQ(2, -0.12+0.75i), jd},
+	}
+	for _, f := range fracs {
+		f.general.Data().SetEscapeCriterion(gofrac.ModulusCriterion{})
+		for _, maxIt := range []int{1, 2, 50} {
+			want, _ := gofrac.FracIt(f.d, f.general, maxIt)
+			got, _ := gofrac.FracIt(f.d, f.plain, maxIt)
+			rows, cols := f.d.Dimensions()
+			for row := 0; row < rows; row++ {
+				for col := 0; col < cols; col++ {
+					if w, g := *want.At(row, col), *got.At(row, col); w != g {
+						t.Errorf("%T: %d iterations: (row, col) = (%d, %d): want: %+v, got: %+v", f.plain, maxIt, row, col, w, g)
+					}
+				}
+			}
+		}
+	}
+}
@@ -6,6 +6,7 @@
 package gofrac
 
 import (
+	"context"
 	"errors"
 	"image"
 )
@@ -20,25 +21,67 @@ import (
 // MaxIterations gives the number of iterations to be performed before
 // considering a point to have converged.
 func GetImage(f Fraccer, d DomainReader, plotter Plotter, palette ColorSampler, maxIterations int) (*image.RGBA, error) {
+	return GetImageContext(context.Background(), f, d, plotter, palette, maxIterations)
+}
+
+// GetImageContext is like GetImage, but abandons the calculation as soon as
+// ctx is cancelled, in which case it returns ctx.Err().
+func GetImageContext(ctx context.Context, f Fraccer, d DomainReader, plotter Plotter, palette ColorSampler, maxIterations int) (*image.RGBA, error) {
+	return GetImageOptions(ctx, f, d, plotter, palette, maxIterations, nil)
+}
+
+// GetImageOptions is like GetImageContext, but its behavior can be further
+// configured with opts, which may be nil. Progress is reported for the
+// StageFrac and StageRender stages in turn.
+//
+// If plotter is a Configurer, the features of f it enables only last until
+// GetImageOptions returns. Since f is configured in place, concurrent calls
+// must not share it.
+func GetImageOptions(ctx context.Context, f Fraccer, d DomainReader, plotter Plotter, palette ColorSampler, maxIterations int, opts *Options) (*image.RGBA, error) {
 	if maxIterations < 1 {
 		return nil, errors.New("gofrac: maximum iteration count must be greater than zero")
 	}
 
+	restore := configure(plotter, f.Data(), d)
+	defer restore()
 	f.SetMaxIterations(maxIterations)
 	plotter.SetFracData(f.Data())
 
-	results, err := FracIt(d, f, maxIterations)
+	results, err := FracItOptions(ctx, opts.supersampling().fracDomain(d), f, maxIterations, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	bitmap := Render(results, plotter, palette)
-	h, w := d.Dimensions()
+	if opts.supersampling().N < 2 {
+		return renderImage(ctx, results, plotter, palette, opts)
+	}
+	bitmap, err := supersample(ctx, f, d, results, plotter, palette, opts)
+	if err != nil {
+		return nil, err
+	}
+	return bitmap.image(), nil
+}
+
+// renderImage renders results into an image.RGBA.
+func renderImage(ctx context.Context, results *Results, plotter Plotter, palette ColorSampler, opts *Options) (*image.RGBA, error) {
+	bitmap, err := RenderOptions(ctx, results, plotter, palette, opts)
+	if err != nil {
+		return nil, err
+	}
+	return bitmap.image(), nil
+}
+
+// image converts a bitmap into an image.RGBA.
+func (bitmap bitmap) image() *image.RGBA {
+	h, w := len(bitmap), 0
+	if h > 0 {
+		w = len(bitmap[0])
+	}
 	img := image.NewRGBA(image.Rect(0, 0, w, h))
 	for y, row := range bitmap {
 		for x, clr := range row {
 			img.Set(x, y, clr)
 		}
 	}
-	return img, nil
+	return img
 }
@@ -0,0 +1,259 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// defaultCycleTolerance is the distance within which two iterates are
+// considered to be the same point of a cycle if FracData doesn't say
+// otherwise.
+const defaultCycleTolerance = 1e-10
+
+func (f *FracData) cycleTolerance() float64 {
+	if f.CycleTolerance > 0 {
+		return f.CycleTolerance
+	}
+	return defaultCycleTolerance
+}
+
+// cycle finds the attracting cycle approached by the bounded orbit of
+// z_{n+1} = v(z_n) + c through z, using Brent's cycle detection. It returns
+// the period of the cycle and its multiplier, the product of the derivatives
+// dv along the cycle, or 0 and 0 if the orbit doesn't settle on a cycle
+// within MaxIterations further iterations. The multiplier is 0 if dv is nil.
+func (f *FracData) cycle(z complex128, c complex128, v CCMap, dv CCMap) (period int, multiplier complex128) {
+	tol := f.cycleTolerance()
+	power, lam := 1, 1
+	tortoise, hare := z, v(z)+c
+	for steps := 0; cmplx.Abs(hare-tortoise) > tol; steps++ {
+		if steps == f.MaxIterations {
+			return 0, 0
+		}
+		// the orbit wasn't bounded after all, and would otherwise end in a
+		// NaN distance that passes for a cycle
+		if cmplx.IsInf(hare) || cmplx.IsNaN(hare) {
+			return 0, 0
+		}
+		if power == lam {
+			tortoise = hare
+			power *= 2
+			lam = 0
+		}
+		hare = v(hare) + c
+		lam++
+	}
+
+	if dv == nil {
+		return lam, 0
+	}
+	multiplier = 1
+	for i := 0; i < lam; i++ {
+		multiplier *= dv(hare)
+		hare = v(hare) + c
+	}
+	return lam, multiplier
+}
+
+// interiorDistance estimates the distance of c to the boundary of the
+// Mandelbrot set, given that its orbit is attracted to a cycle of period p
+// through z. See https://www.mrob.com/pub/muency/interiordistanceestimate.html
+func interiorDistance(z complex128, c complex128, p int) float64 {
+	// refine z to the periodic point with Newton's method on f^p(z) - z
+	for i := 0; i < 8; i++ {
+		w, dw := z, complex128(1)
+		for j := 0; j < p; j++ {
+			dw *= 2 * w
+			w = w*w + c
+		}
+		if dw == 1 {
+			break
+		}
+		step := (w - z) / (dw - 1)
+		z -= step
+		if cmplx.Abs(step) < 1e-15*cmplx.Abs(z) {
+			break
+		}
+	}
+
+	// derivatives of f^p with respect to z and c, and the second derivatives
+	// d^2/dz^2 and d^2/dzdc, at the periodic point
+	w := z
+	var dz, dc, dzdz, dzdc complex128 = 1, 0, 0, 0
+	for j := 0; j < p; j++ {
+		dzdz, dzdc = 2*(dz*dz+w*dzdz), 2*(dz*dc+w*dzdc)
+		dz, dc = 2*w*dz, 2*w*dc+1
+		w = w*w + c
+	}
+
+	mod := cmplx.Abs(dz)
+	den := cmplx.Abs(dzdc + dzdz*dc/(1-dz))
+	if mod >= 1 || den == 0 {
+		return 0
+	}
+	return (1 - mod*mod) / den
+}
+
+// interiorPlot plots a Result inside the set with pFunc if its orbit was
+// found to approach a cycle, and outside of the set with exterior if it is
+// non-nil. Other Results are plotted by their escape times.
+func (pb *PlotterBase) interiorPlot(r *Result, exterior Plotter, pFunc func(r *Result) float64) float64 {
+	if r.Iterations < pb.MaxIterations-1 && !r.Converged {
+		if exterior != nil {
+			return exterior.Plot(r)
+		}
+		return float64(r.Iterations)
+	}
+	if r.Stats == nil || r.Stats.Period == 0 {
+		return float64(pb.MaxIterations - 1)
+	}
+	return pFunc(r)
+}
+
+// configureInterior enables cycle detection in fd and passes the
+// configuration on to the exterior plotter.
+func configureInterior(fd *FracData, d DomainReader, exterior Plotter) {
+	fd.SetCycles(true)
+	if c, ok := exterior.(Configurer); ok {
+		c.Configure(fd, d)
+	}
+}
+
+// PeriodPlotter colors the interior of a set by the period of the attracting
+// cycle that each orbit approaches. It is best paired with a PeriodicPalette
+// with a Period of 1, which gives each period its own band. Points outside of
+// the set are plotted by Exterior, or by escape time if it is nil.
+type PeriodPlotter struct {
+	PlotterBase
+	Exterior Plotter
+}
+
+func (p *PeriodPlotter) SetFracData(fd *FracData) {
+	p.PlotterBase.SetFracData(fd)
+	if p.Exterior != nil {
+		p.Exterior.SetFracData(fd)
+	}
+}
+
+func (p *PeriodPlotter) Configure(fd *FracData, d DomainReader) {
+	configureInterior(fd, d, p.Exterior)
+}
+
+func (p PeriodPlotter) Plot(r *Result) float64 {
+	return p.interiorPlot(r, p.Exterior, func(r *Result) float64 {
+		// with a single iteration, every value is convergent, as in
+		// isConvergent
+		if p.MaxIterations <= 1 {
+			return float64(p.MaxIterations - 1)
+		}
+		return float64(r.Stats.Period % (p.MaxIterations - 1))
+	})
+}
+
+// MultiplierMagnitudePlotter colors the interior of a set by the magnitude of
+// the multiplier of the attracting cycle that each orbit approaches, which
+// is 0 at the center of a component and 1 on its boundary. Points outside of
+// the set are plotted by Exterior, or by escape time if it is nil.
+type MultiplierMagnitudePlotter struct {
+	PlotterBase
+	Exterior Plotter
+}
+
+func (p *MultiplierMagnitudePlotter) SetFracData(fd *FracData) {
+	p.PlotterBase.SetFracData(fd)
+	if p.Exterior != nil {
+		p.Exterior.SetFracData(fd)
+	}
+}
+
+func (p *MultiplierMagnitudePlotter) Configure(fd *FracData, d DomainReader) {
+	configureInterior(fd, d, p.Exterior)
+}
+
+func (p MultiplierMagnitudePlotter) Plot(r *Result) float64 {
+	return p.interiorPlot(r, p.Exterior, func(r *Result) float64 {
+		return float64(p.MaxIterations-2) * math.Min(1, cmplx.Abs(r.Stats.Multiplier))
+	})
+}
+
+// MultiplierPhasePlotter colors the interior of a set by the phase of the
+// multiplier of the attracting cycle that each orbit approaches. Points
+// outside of the set are plotted by Exterior, or by escape time if it is nil.
+type MultiplierPhasePlotter struct {
+	PlotterBase
+	Exterior Plotter
+}
+
+func (p *MultiplierPhasePlotter) SetFracData(fd *FracData) {
+	p.PlotterBase.SetFracData(fd)
+	if p.Exterior != nil {
+		p.Exterior.SetFracData(fd)
+	}
+}
+
+func (p *MultiplierPhasePlotter) Configure(fd *FracData, d DomainReader) {
+	configureInterior(fd, d, p.Exterior)
+}
+
+func (p MultiplierPhasePlotter) Plot(r *Result) float64 {
+	return p.interiorPlot(r, p.Exterior, func(r *Result) float64 {
+		return float64(p.MaxIterations-2) * (cmplx.Phase(r.Stats.Multiplier) + math.Pi) / (2 * math.Pi)
+	})
+}
+
+// InteriorDistancePlotter colors the interior of the Mandelbrot set by the
+// estimated distance of each point to the boundary, measured in pixels. Like
+// the DistanceEstimatorPlotter, it plots points near the boundary at the top
+// end of the palette. Points outside of the set are plotted by Exterior, or
+// by escape time if it is nil.
+type InteriorDistancePlotter struct {
+	PlotterBase
+	Exterior Plotter
+
+	// PixelSize is the distance between neighboring samples. If it is zero,
+	// it is measured from the domain by Configure.
+	PixelSize float64
+
+	// Scale is the distance, in pixels, over which the palette is spread. If
+	// it is zero, it defaults to 4 pixels.
+	Scale float64
+
+	pixelSize float64
+}
+
+func (p *InteriorDistancePlotter) SetFracData(fd *FracData) {
+	p.PlotterBase.SetFracData(fd)
+	if p.Exterior != nil {
+		p.Exterior.SetFracData(fd)
+	}
+}
+
+func (p *InteriorDistancePlotter) Configure(fd *FracData, d DomainReader) {
+	configureInterior(fd, d, p.Exterior)
+	if d != nil {
+		p.pixelSize = PixelSize(d)
+	}
+}
+
+func (p InteriorDistancePlotter) Plot(r *Result) float64 {
+	return p.interiorPlot(r, p.Exterior, func(r *Result) float64 {
+		px := p.PixelSize
+		if px == 0 {
+			px = p.pixelSize
+		}
+		scale := p.Scale
+		if scale == 0 {
+			scale = defaultDEScale
+		}
+
+		t := r.Stats.InteriorDistance
+		if px > 0 {
+			t /= px
+		}
+		return float64(p.MaxIterations-2) * (1 - math.Tanh(t/scale))
+	})
+}
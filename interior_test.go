@@ -0,0 +1,189 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac_test
+
+import (
+	"github.com/cfdwalrus/gofrac"
+	"image/color"
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+// statsOf returns the statistics of r, or the zero Statistics if it has none.
+func statsOf(r *gofrac.Result) gofrac.Statistics {
+	if r.Stats == nil {
+		return gofrac.Statistics{}
+	}
+	return *r.Stats
+}
+
+func TestMandelbrot_Cycles(t *testing.T) {
+	m := gofrac.NewMandelbrot(2)
+	m.SetMaxIterations(1000)
+	m.SetCycles(true)
+
+	// the fixed point of z^2 + c is (1 - sqrt(1 - 4c)) / 2, and its
+	// multiplier is twice that
+	c := -0.1 + 0.1i
+	fixed := (1 - cmplx.Sqrt(1-4*c)) / 2
+	tests := []struct {
+		c          complex128
+		period     int
+		multiplier complex128
+	}{
+		{0, 1, 0},
+		{c, 1, 2 * fixed},
+		{-1, 2, 0},
+		{-0.12 + 0.75i, 3, cmplx.NaN()},
+		{-1.31, 4, cmplx.NaN()},
+	}
+	for _, test := range tests {
+		r := statsOf(m.Frac(test.c))
+		if r.Period != test.period {
+			t.Errorf("%T: c = %v: want: period %d, got: period %d", m, test.c, test.period, r.Period)
+		}
+		if !cmplx.IsNaN(test.multiplier) && cmplx.Abs(r.Multiplier-test.multiplier) > 1e-6 {
+			t.Errorf("%T: c = %v: want: multiplier %v, got: multiplier %v", m, test.c, test.multiplier, r.Multiplier)
+		}
+		if cmplx.Abs(r.Multiplier) >= 1 {
+			t.Errorf("%T: c = %v: want: attracting cycle, got: multiplier %v", m, test.c, r.Multiplier)
+		}
+	}
+
+	// escaping orbits have no period
+	if r := statsOf(m.Frac(1)); r.Period != 0 {
+		t.Errorf("%T: want: period 0, got: period %d", m, r.Period)
+	}
+
+	// ... even if they only escape with the last iterate, which overflows
+	// for a large bailout radius
+	m = gofrac.NewMandelbrot(1e150)
+	m.SetCycles(true)
+	for maxIt := 2; maxIt <= 16; maxIt++ {
+		m.SetMaxIterations(maxIt)
+		if r := statsOf(m.Frac(1)); r.Period != 0 {
+			t.Errorf("%T: %d iterations: want: period 0, got: period %d", m, maxIt, r.Period)
+		}
+	}
+}
+
+// leftCriterion lets iterates escape to the left of Re(z) = -0.95.
+type leftCriterion struct{}
+
+func (leftCriterion) Check(z complex128, _ complex128, _ float64) gofrac.Status {
+	if real(z) < -0.95 {
+		return gofrac.Escaped
+	}
+	return gofrac.Iterating
+}
+
+func TestJuliaQ_Cycles_LastIterate(t *testing.T) {
+	// the orbit of 0.5 approaches the 2-cycle {0, -1} of z^2 - 1, but
+	// escapes with z_9 = -0.990 before it gets there
+	j := gofrac.NewJuliaQ(2, -1)
+	j.SetEscapeCriterion(leftCriterion{})
+	j.SetCycles(true)
+	tests := []struct {
+		maxIt, period int
+	}{
+		{8, 2},
+		{9, 0},
+		{10, 0},
+	}
+	for _, test := range tests {
+		j.SetMaxIterations(test.maxIt)
+		if r := statsOf(j.Frac(0.5)); r.Period != test.period {
+			t.Errorf("%T: %d iterations: want: period %d, got: period %d", j, test.maxIt, test.period, r.Period)
+		}
+	}
+}
+
+func TestMandelbrot_InteriorDistance(t *testing.T) {
+	m := gofrac.NewMandelbrot(2)
+	m.SetMaxIterations(1000)
+	m.SetCycles(true)
+
+	// the nearest point on the boundary of the main cardioid to 0 is its cusp
+	// at 0.25, and the estimate is good to within a factor of 4
+	if d := statsOf(m.Frac(0)).InteriorDistance; d < 0.25/4 || d > 0.25*4 {
+		t.Errorf("%T: want: 0.0625 <= d <= 1, got: %0.5f", m, d)
+	}
+	if d := statsOf(m.Frac(0.24)).InteriorDistance; d <= 0 || d > 0.04 {
+		t.Errorf("%T: want: 0 < d <= 0.04, got: %0.5f", m, d)
+	}
+}
+
+func TestJuliaQ_Cycles(t *testing.T) {
+	// the Julia set of the rabbit has an attracting cycle of period 3
+	j := gofrac.NewJuliaQ(2, -0.12+0.75i)
+	j.SetMaxIterations(1000)
+	j.SetCycles(true)
+	if r := statsOf(j.Frac(0)); r.Period != 3 {
+		t.Errorf("%T: want: period 3, got: period %d", j, r.Period)
+	}
+}
+
+func TestInteriorPlotters(t *testing.T) {
+	f := gofrac.FracData{Radius: 2, MaxIterations: 100}
+	f.SetDegree(2)
+	inside := gofrac.Result{Iterations: 99, Stats: &gofrac.Statistics{Period: 3, Multiplier: 0.5i, InteriorDistance: 0}}
+	outside := gofrac.Result{Iterations: 10, Z: 3}
+	unknown := gofrac.Result{Iterations: 99}
+
+	var escape gofrac.EscapeTimePlotter
+	tests := []struct {
+		p      gofrac.Plotter
+		inside float64
+	}{
+		{&gofrac.PeriodPlotter{Exterior: &escape}, 3},
+		{&gofrac.MultiplierMagnitudePlotter{Exterior: &escape}, 49},
+		{&gofrac.MultiplierPhasePlotter{Exterior: &escape}, 98 * 0.75},
+		{&gofrac.InteriorDistancePlotter{Exterior: &escape, PixelSize: 0.01}, 98},
+	}
+	for _, test := range tests {
+		test.p.SetFracData(&f)
+		if got := test.p.Plot(&inside); math.Abs(got-test.inside) > 1e-9 {
+			t.Errorf("%T: inside: want: %0.2f, got: %0.2f", test.p, test.inside, got)
+		}
+		if got := test.p.Plot(&outside); got != 10 {
+			t.Errorf("%T: outside: want: %0.2f, got: %0.2f", test.p, 10.0, got)
+		}
+		if got := test.p.Plot(&unknown); got != 99 {
+			t.Errorf("%T: no cycle: want: %0.2f, got: %0.2f", test.p, 99.0, got)
+		}
+	}
+
+	// configuring a plotter enables cycle detection for a single image
+	m := gofrac.NewMandelbrot(2)
+	d, _ := gofrac.NewDomain(-2, -1, 1, 1, 30, 20)
+	p := &gofrac.PeriodPlotter{}
+	if _, err := gofrac.GetImage(m, d, p, gofrac.SpectralPalette{Sweep: 360}, 100); err != nil {
+		t.Fatal(err)
+	}
+	if !p.Cycles {
+		t.Errorf("%T: want: cycle detection enabled, got: disabled", p)
+	}
+	if m.Cycles {
+		t.Errorf("%T: want: cycle detection disabled after the image, got: enabled", m)
+	}
+}
+
+func TestPeriodPlotter_SingleIteration(t *testing.T) {
+	// a single iteration leaves no room for periods, but mustn't panic
+	m := gofrac.NewMandelbrot(2)
+	d, _ := gofrac.NewDomain(-2, -1, 1, 1, 30, 20)
+	pal := gofrac.PeriodicPalette{BandedPalette: gofrac.NewUniformBandedPalette(color.White), Period: 1}
+	if _, err := gofrac.GetImage(m, d, &gofrac.PeriodPlotter{}, pal, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	f := gofrac.FracData{Radius: 2, MaxIterations: 1}
+	var p gofrac.PeriodPlotter
+	p.SetFracData(&f)
+	if got := p.Plot(&gofrac.Result{Stats: &gofrac.Statistics{Period: 2}}); got != 0 {
+		t.Errorf("%T: want: %0.2f, got: %0.2f", p, 0.0, got)
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac
+
+import "errors"
+
+// BasicFamily returns the member B_m of Kalantari's basic family of iteration
+// functions for the polynomial p. B_2 is Newton's method, B_3 is Halley's
+// method, and higher members converge with order m. In terms of the
+// determinants D_k of the Toeplitz matrices built from the Taylor coefficients
+// of p,
+//
+//	B_m(z) = z - p(z) D_{m-2}(z) / D_{m-1}(z).
+//
+// See Kalantari, "Polynomial Root-Finding and Polynomiography" (2008).
+func BasicFamily(p Polynomial, m int) (CCMap, error) {
+	if m < 2 {
+		return nil, errors.New("gofrac: the basic family starts at m = 2")
+	}
+	if p.Degree() < 1 {
+		return nil, errors.New("gofrac: a constant polynomial has no roots to find")
+	}
+
+	return func(z complex128) complex128 {
+		a := p.Taylor(z, m-1)
+
+		// D_0 = 1, D_k = sum_{i=1}^{k} (-1)^{i-1} p^{i-1} a_i D_{k-i}
+		d := make([]complex128, m)
+		d[0] = 1
+		for k := 1; k < m; k++ {
+			pPow := complex128(1)
+			for i := 1; i <= k; i++ {
+				d[k] += pPow * a[i] * d[k-i]
+				pPow *= -a[0]
+			}
+		}
+		return z - a[0]*d[m-2]/d[m-1]
+	}, nil
+}
+
+// EulerSchroderFamily returns the member E_m of the Euler-Schröder family of
+// iteration functions for the polynomial p. E_2 is Newton's method, E_3 is
+// Chebyshev's method, and higher members converge with order m. E_m truncates
+// the Taylor series of the local inverse of p,
+//
+//	E_m(z) = z + sum_{k=1}^{m-1} (p^{-1})^{(k)}(p(z)) (-p(z))^k / k!,
+//
+// whose coefficients are found by reverting the Taylor series of p about z.
+func EulerSchroderFamily(p Polynomial, m int) (CCMap, error) {
+	if m < 2 {
+		return nil, errors.New("gofrac: the Euler-Schröder family starts at m = 2")
+	}
+	if p.Degree() < 1 {
+		return nil, errors.New("gofrac: a constant polynomial has no roots to find")
+	}
+
+	return func(z complex128) complex128 {
+		a := p.Taylor(z, m-1)
+		b := revertSeries(a)
+
+		sum := z
+		pPow := complex128(1)
+		for k := 1; k < m; k++ {
+			pPow *= -a[0]
+			sum += b[k] * pPow
+		}
+		return sum
+	}, nil
+}
+
+// revertSeries computes the coefficients b_1, ..., b_{n-1} of the series
+// h(u) = b_1 u + b_2 u^2 + ... satisfying a_1 h + a_2 h^2 + ... = u, where n
+// is the length of a. a_0 and b_0 are ignored.
+func revertSeries(a []complex128) []complex128 {
+	n := len(a)
+	b := make([]complex128, n)
+	if n < 2 {
+		return b
+	}
+	b[1] = 1 / a[1]
+
+	for k := 2; k < n; k++ {
+		// the coefficient of u^k in sum_{j=2}^{k} a_j h^j, where h only
+		// includes the terms found so far
+		var sum complex128
+		pow := make([]complex128, k+1)
+		copy(pow, b[:k])
+		for j := 2; j <= k; j++ {
+			pow = mulSeries(pow, b[:k], k)
+			sum += a[j] * pow[k]
+		}
+		b[k] = -sum / a[1]
+	}
+	return b
+}
+
+// mulSeries multiplies two power series, truncating the result after the
+// term of degree n.
+func mulSeries(s []complex128, t []complex128, n int) []complex128 {
+	prod := make([]complex128, n+1)
+	for i, x := range s {
+		if i > n || x == 0 {
+			continue
+		}
+		for j, y := range t {
+			if i+j > n {
+				break
+			}
+			prod[i+j] += x * y
+		}
+	}
+	return prod
+}
+
+// JuliaPG is a Julia-style polynomiograph, the counterpart of MandelPG. For
+// every z_0 in the domain, it iterates z_{n+1} = B(z_n) - C for a fixed
+// complex number C until successive iterates are within eps of each other.
+type JuliaPG struct {
+	FracData
+
+	// B is a root-finding iteration function, such as one produced by
+	// BasicFamily or EulerSchroderFamily.
+	B CCMap
+
+	C complex128
+
+	// eps is the convergence threshold.
+	eps float64
+}
+
+// NewJuliaPG returns a new structure with the members required for Julia-style
+// polynomiograph calculations.
+func NewJuliaPG(eps float64, B CCMap, c complex128) *JuliaPG {
+	return &JuliaPG{
+		B:   B,
+		C:   c,
+		eps: eps,
+	}
+}
+
+func (j JuliaPG) Frac(loc complex128) *Result {
+	z := loc
+	count := 0
+	converged := false
+	crit := j.criterion(ConvergenceCriterion{Epsilon: j.eps})
+	o := j.newOrbit(z, -j.C)
+	for {
+		zNext := j.B(z) - j.C
+		o.visit(zNext, count+1)
+		if status := crit.Check(zNext, z, j.Radius); status != Iterating {
+			converged = status == Converged
+			break
+		}
+		z = zNext
+		if count == j.MaxIterations-1 {
+			break
+		}
+		count++
+	}
+
+	return o.done(&Result{
+		Z:          z,
+		C:          j.C,
+		Iterations: count,
+		Converged:  converged,
+	})
+}
@@ -0,0 +1,107 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac_test
+
+import (
+	"github.com/cfdwalrus/gofrac"
+	"math/cmplx"
+	"testing"
+)
+
+// p(z) = z^3 - 2z + 2
+var testPoly = gofrac.Polynomial{2, -2, 0, 1}
+
+func testPolyDerivs(z complex128) (p, dp, d2p complex128) {
+	return z*z*z - 2*z + 2, 3*z*z - 2, 6 * z
+}
+
+func TestBasicFamily(t *testing.T) {
+	newton, _ := gofrac.BasicFamily(testPoly, 2)
+	halley, _ := gofrac.BasicFamily(testPoly, 3)
+
+	for _, z := range []complex128{0.5 + 0.5i, -1.7, 2 - 1i} {
+		p, dp, d2p := testPolyDerivs(z)
+
+		want := z - p/dp
+		if got := newton(z); cmplx.Abs(want-got) > 1e-12 {
+			t.Errorf("B_2(%v): want: %v, got: %v", z, want, got)
+		}
+
+		want = z - 2*p*dp/(2*dp*dp-p*d2p)
+		if got := halley(z); cmplx.Abs(want-got) > 1e-12 {
+			t.Errorf("B_3(%v): want: %v, got: %v", z, want, got)
+		}
+	}
+
+	if _, err := gofrac.BasicFamily(testPoly, 1); err == nil {
+		t.Errorf("Error not caught for m < 2")
+	}
+	if _, err := gofrac.BasicFamily(gofrac.Polynomial{1}, 3); err == nil {
+		t.Errorf("Error not caught for constant polynomial")
+	}
+}
+
+func TestEulerSchroderFamily(t *testing.T) {
+	newton, _ := gofrac.EulerSchroderFamily(testPoly, 2)
+	chebyshev, _ := gofrac.EulerSchroderFamily(testPoly, 3)
+
+	for _, z := range []complex128{0.5 + 0.5i, -1.7, 2 - 1i} {
+		p, dp, d2p := testPolyDerivs(z)
+
+		want := z - p/dp
+		if got := newton(z); cmplx.Abs(want-got) > 1e-12 {
+			t.Errorf("E_2(%v): want: %v, got: %v", z, want, got)
+		}
+
+		want = z - p/dp - d2p*p*p/(2*dp*dp*dp)
+		if got := chebyshev(z); cmplx.Abs(want-got) > 1e-12 {
+			t.Errorf("E_3(%v): want: %v, got: %v", z, want, got)
+		}
+	}
+}
+
+func TestFamilies_Convergence(t *testing.T) {
+	roots, _ := testPoly.Roots()
+	z0 := roots[0] + 0.05
+
+	// the error of a method of order m shrinks like |e|^m, so every member
+	// should land on the root after a few steps
+	for m := 2; m <= 6; m++ {
+		for _, family := range []func(gofrac.Polynomial, int) (gofrac.CCMap, error){
+			gofrac.BasicFamily,
+			gofrac.EulerSchroderFamily,
+		} {
+			f, err := family(testPoly, m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			z := z0
+			for i := 0; i < 6; i++ {
+				z = f(z)
+			}
+			if cmplx.Abs(z-roots[0]) > 1e-10 {
+				t.Errorf("m = %d: want: %v, got: %v", m, roots[0], z)
+			}
+		}
+	}
+}
+
+func TestJuliaPG_Frac(t *testing.T) {
+	roots := []complex128{1, -1}
+	newton, _ := gofrac.BasicFamily(gofrac.NewPolynomialFromRoots(roots...), 2)
+	j := gofrac.NewJuliaPG(1e-9, newton, 0)
+	j.SetMaxIterations(100)
+
+	// with C = 0, the Julia-style polynomiograph is plain root finding
+	for _, z := range []complex128{2, -0.5 + 0.1i} {
+		got := j.Frac(z)
+		if cmplx.Abs(got.Z-1) > 1e-6 && cmplx.Abs(got.Z+1) > 1e-6 {
+			t.Errorf("%T: z_0 = %v: want: a root, got: %v", j, z, got.Z)
+		}
+		if got.Iterations >= 99 {
+			t.Errorf("%T: z_0 = %v: want: convergence, got: %d iterations", j, z, got.Iterations)
+		}
+	}
+}
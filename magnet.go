@@ -0,0 +1,100 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac
+
+// The magnet fractals arise from the renormalization transformations of the
+// Ising model of magnetism. Besides escaping to infinity, their iterates may
+// converge to the finite attractor z = 1. By default, both outcomes are tested
+// for, using the bailout radius and convergence threshold (Epsilon) of
+// FracData.
+
+// magnet iterates z_{n+1} = m(z_n, c) from z_0 = 0 until the iterate escapes
+// or converges.
+func (f *FracData) magnet(c complex128, m func(z, c complex128) complex128) *Result {
+	crit := f.criterion(CombinedCriterion{
+		ModulusCriterion{},
+		AttractorCriterion{Attractor: 1, Epsilon: f.Epsilon},
+	})
+	z, zPrev := complex128(0), complex128(0)
+	count := 0
+	o := f.newOrbit(z, c)
+	status := crit.Check(z, zPrev, f.Radius)
+	for status == Iterating {
+		z, zPrev = m(z, c), z
+		o.visit(z, count+1)
+		if count == f.MaxIterations-1 {
+			break
+		}
+		count++
+		status = crit.Check(z, zPrev, f.Radius)
+	}
+	return o.done(&Result{
+		Z:          z,
+		ZPrev:      zPrev,
+		C:          c,
+		Iterations: count,
+		Converged:  status == Converged,
+	})
+}
+
+// MagnetI results from iterating
+// z_{n+1} = ((z_n^2 + c - 1) / (2z_n + c - 2))^2 for all complex numbers c,
+// with z_0 = 0.
+type MagnetI struct {
+	FracData
+}
+
+// NewMagnetI constructs a MagnetI struct with a given bailout radius and
+// convergence threshold.
+func NewMagnetI(radius float64, eps float64) *MagnetI {
+	m := &MagnetI{
+		FracData{
+			Radius:  radius,
+			Epsilon: eps,
+		},
+	}
+	m.SetDegree(2.0)
+	return m
+}
+
+func magnetI(z, c complex128) complex128 {
+	w := (z*z + c - 1) / (2*z + c - 2)
+	return w * w
+}
+
+func (m MagnetI) Frac(loc complex128) *Result {
+	return m.magnet(loc, magnetI)
+}
+
+// MagnetII results from iterating
+// z_{n+1} = ((z_n^3 + 3(c-1)z_n + (c-1)(c-2)) /
+// (3z_n^2 + 3(c-2)z_n + (c-1)(c-2) + 1))^2 for all complex numbers c, with
+// z_0 = 0.
+type MagnetII struct {
+	FracData
+}
+
+// NewMagnetII constructs a MagnetII struct with a given bailout radius and
+// convergence threshold.
+func NewMagnetII(radius float64, eps float64) *MagnetII {
+	m := &MagnetII{
+		FracData{
+			Radius:  radius,
+			Epsilon: eps,
+		},
+	}
+	m.SetDegree(2.0)
+	return m
+}
+
+func magnetII(z, c complex128) complex128 {
+	c1, c2 := c-1, c-2
+	w := (z*z*z + 3*c1*z + c1*c2) / (3*z*z + 3*c2*z + c1*c2 + 1)
+	return w * w
+}
+
+func (m MagnetII) Frac(loc complex128) *Result {
+	return m.magnet(loc, magnetII)
+}
@@ -0,0 +1,43 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac_test
+
+import (
+	"github.com/cfdwalrus/gofrac"
+	"math/cmplx"
+	"testing"
+)
+
+func TestMagnet_Frac(t *testing.T) {
+	maxIt := 200
+	eps := 1e-6
+
+	tc := []struct {
+		f         gofrac.Fraccer
+		c         complex128
+		converged bool
+		bounded   bool
+	}{
+		{gofrac.NewMagnetI(100, eps), 10, true, false},
+		{gofrac.NewMagnetI(100, eps), -2, true, false},
+		{gofrac.NewMagnetI(100, eps), 0.5i, false, true},
+		{gofrac.NewMagnetII(100, eps), 3, true, false},
+		{gofrac.NewMagnetII(100, eps), 1.5 + 1i, false, false},
+		{gofrac.NewMagnetII(100, eps), 0.5i, false, true},
+	}
+
+	for _, tc := range tc {
+		tc.f.SetMaxIterations(maxIt)
+		got := tc.f.Frac(tc.c)
+		switch {
+		case got.Converged != tc.converged:
+			t.Errorf("%T: c = %v: want: Converged = %t, got: %t", tc.f, tc.c, tc.converged, got.Converged)
+		case tc.converged && cmplx.Abs(got.Z-1) >= eps:
+			t.Errorf("%T: c = %v: want: Z = 1, got: %v", tc.f, tc.c, got.Z)
+		case tc.bounded != (got.Iterations == maxIt-1):
+			t.Errorf("%T: c = %v: want: bounded = %t, got: %d iterations", tc.f, tc.c, tc.bounded, got.Iterations)
+		}
+	}
+}
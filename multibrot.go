@@ -0,0 +1,121 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Multi stores the information needed by a fractal of the form
+// z_{n+1} = z_n^d + c, where the exponent d may be any complex number.
+type Multi struct {
+	FracData
+	exponent complex128
+	pow      CCMap
+	dPow     CCMap
+}
+
+// NewMulti constructs a Multi struct with a given bailout radius and exponent.
+func NewMulti(radius float64, d complex128) Multi {
+	m := Multi{
+		FracData: FracData{
+			Radius: radius,
+		},
+	}
+	m.SetExponent(d)
+	return m
+}
+
+// Exponent returns the exponent d in z^d + c.
+func (m *Multi) Exponent() complex128 {
+	return m.exponent
+}
+
+// SetExponent sets the exponent d in z^d + c. Integer exponents are computed
+// by repeated multiplication, and all others by cmplx.Pow. The degree used for
+// smoothing is the real part of d, which governs how quickly escaping iterates
+// grow.
+func (m *Multi) SetExponent(d complex128) {
+	m.exponent = d
+	m.SetDegree(real(d))
+
+	n := real(d)
+	if imag(d) == 0 && n == math.Trunc(n) && math.Abs(n) <= math.MaxInt32 {
+		m.pow = func(z complex128) complex128 {
+			return intPow(z, int(n))
+		}
+		m.dPow = func(z complex128) complex128 {
+			return d * intPow(z, int(n)-1)
+		}
+		return
+	}
+	m.pow = func(z complex128) complex128 {
+		return cmplx.Pow(z, d)
+	}
+	m.dPow = func(z complex128) complex128 {
+		return d * cmplx.Pow(z, d-1)
+	}
+}
+
+// intPow computes z^n by repeated squaring.
+func intPow(z complex128, n int) complex128 {
+	if n < 0 {
+		return 1 / intPow(z, -n)
+	}
+
+	p := complex128(1)
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			p *= z
+		}
+		z *= z
+	}
+	return p
+}
+
+// Multibrot is the generalization of the Mandelbrot set that results from
+// iterating f_c(z) = z^d + c for all complex numbers c. For exponents with a
+// positive real part, z_0 = 0. Otherwise, 0^d is undefined and z_0 = c, which
+// is equivalent to skipping the first iteration.
+type Multibrot struct {
+	Multi
+}
+
+// NewMultibrot constructs a Multibrot struct with a given bailout radius and
+// exponent d.
+func NewMultibrot(radius float64, d complex128) *Multibrot {
+	return &Multibrot{
+		Multi: NewMulti(radius, d),
+	}
+}
+
+func (m Multibrot) Frac(loc complex128) *Result {
+	if real(m.exponent) > 0 {
+		return m.iterateDZ(0, loc, m.pow, m.dPow, 0, 1)
+	}
+	return m.iterateDZ(loc, loc, m.pow, m.dPow, 1, 1)
+}
+
+// MultiJulia is the generalization of the quadratic Julia set that results
+// from iterating f_C(z) = z^d + C for all complex numbers z and a given
+// complex number C.
+type MultiJulia struct {
+	Multi
+	C complex128
+}
+
+// NewMultiJulia constructs a MultiJulia struct with a given bailout radius,
+// exponent d, and complex parameter c.
+func NewMultiJulia(radius float64, d complex128, c complex128) *MultiJulia {
+	return &MultiJulia{
+		Multi: NewMulti(radius, d),
+		C:     c,
+	}
+}
+
+func (j MultiJulia) Frac(loc complex128) *Result {
+	return j.iterateDZ(loc, j.C, j.pow, j.dPow, 1, 0)
+}
@@ -0,0 +1,93 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac_test
+
+import (
+	"github.com/cfdwalrus/gofrac"
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestMultiJulia_Frac(t *testing.T) {
+	c := 0.1 - 0.2i
+	z := 0.7 + 0.4i
+
+	tc := []struct {
+		d    complex128
+		want complex128
+	}{
+		{2, z*z + c},
+		{5, z*z*z*z*z + c},
+		{-2, 1/(z*z) + c},
+		{2.5, cmplx.Pow(z, 2.5) + c},
+		{3 + 0.5i, cmplx.Pow(z, 3+0.5i) + c},
+	}
+
+	// a single iteration applies the map once
+	for _, tc := range tc {
+		f := gofrac.NewMultiJulia(100, tc.d, c)
+		f.SetMaxIterations(1)
+		got := f.Frac(z).Z
+		if cmplx.Abs(got-tc.want) > 1e-12 {
+			t.Errorf("%T: d = %v: want: %v, got: %v", f, tc.d, tc.want, got)
+		}
+	}
+}
+
+func TestMultibrot_Frac(t *testing.T) {
+	maxIt := 50
+	m := gofrac.NewMandelbrot(4)
+	m.SetMaxIterations(maxIt)
+	m2 := gofrac.NewMultibrot(4, 2)
+	m2.SetMaxIterations(maxIt)
+
+	// with d = 2, Multibrot is the Mandelbrot set
+	for _, c := range []complex128{0.5 + 0.5i, -0.75 + 0.1i, -2.1, 0.3 - 0.6i} {
+		want, got := m.Frac(c), m2.Frac(c)
+		if want.Iterations != got.Iterations || want.Z != got.Z {
+			t.Errorf("%T: c = %v: want: %v, got: %v", m2, c, *want, *got)
+		}
+	}
+
+	// negative exponents start from z_0 = c and don't blow up at the origin
+	neg := gofrac.NewMultibrot(4, -2)
+	neg.SetMaxIterations(maxIt)
+	if got := neg.Frac(0.5); math.IsNaN(real(got.Z)) {
+		t.Errorf("%T: d = -2: want: a number, got: %v", neg, got.Z)
+	}
+}
+
+func TestMultibrot_SmoothedEscapeTime(t *testing.T) {
+	// the smoothed escape time should be continuous across the bands of the
+	// integer escape time for each degree
+	for _, d := range []complex128{3, 4, 6.5} {
+		m := gofrac.NewMultibrot(1e10, d)
+		m.SetMaxIterations(100)
+		var p gofrac.SmoothedEscapeTimePlotter
+		p.SetFracData(m.Data())
+
+		prev := math.NaN()
+		for x := 0.9; x < 2.0; x += 0.001 {
+			got := p.Plot(m.Frac(complex(x, 0)))
+			if math.Abs(got-prev) > 0.1 {
+				t.Errorf("%T: d = %v: discontinuity at c = %0.3f: %0.3f -> %0.3f", m, d, x, prev, got)
+			}
+			prev = got
+		}
+	}
+}
+
+func TestMultibrot_Derivative(t *testing.T) {
+	for _, d := range []complex128{3, 2.5, -2} {
+		m := gofrac.NewMultibrot(1e10, d)
+		m.SetMaxIterations(5)
+		checkDerivative(t, m, 0.3+0.4i)
+
+		j := gofrac.NewMultiJulia(1e10, d, 0.3+0.4i)
+		j.SetMaxIterations(5)
+		checkDerivative(t, j, 0.5-0.2i)
+	}
+}
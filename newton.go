@@ -0,0 +1,150 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac
+
+import (
+	"github.com/lucasb-eyer/go-colorful"
+	"image/color"
+	"math/cmplx"
+)
+
+// NewtonFractal is the fractal produced by applying the relaxed Newton's
+// method, z_{n+1} = z_n - a p(z_n) / p'(z_n), to a polynomial p for all
+// complex numbers z_0 in the domain. Each point belongs to the basin of the
+// root of p to which its iterates converge.
+type NewtonFractal struct {
+	FracData
+
+	// P is the polynomial whose roots are sought.
+	P Polynomial
+
+	// Roots are the roots of P. The Root field of a Result is an index into
+	// Roots.
+	Roots []complex128
+
+	// Relaxation is the factor a in the iteration above. Values other than
+	// 1 slow or speed up the convergence and distort the basins.
+	Relaxation complex128
+
+	dP  Polynomial
+	eps float64
+}
+
+// NewNewtonFractal constructs a NewtonFractal for the monic polynomial with
+// the given roots. An iterate is considered to have converged once it is
+// within eps of a root.
+func NewNewtonFractal(eps float64, roots ...complex128) *NewtonFractal {
+	p := NewPolynomialFromRoots(roots...)
+	return &NewtonFractal{
+		P:          p,
+		Roots:      roots,
+		Relaxation: 1,
+		dP:         p.Derivative(),
+		eps:        eps,
+	}
+}
+
+// NewNewtonFractalFromCoefficients constructs a NewtonFractal for the
+// polynomial p. Its roots are found numerically, and an error is returned if
+// that fails. An iterate is considered to have converged once it is within eps
+// of a root.
+func NewNewtonFractalFromCoefficients(eps float64, p Polynomial) (*NewtonFractal, error) {
+	roots, err := p.Roots()
+	if err != nil {
+		return nil, err
+	}
+	return &NewtonFractal{
+		P:          p,
+		Roots:      roots,
+		Relaxation: 1,
+		dP:         p.Derivative(),
+		eps:        eps,
+	}, nil
+}
+
+// root returns the index of the root within eps of z, or -1 if there is none.
+func (n NewtonFractal) root(z complex128) int {
+	for i, r := range n.Roots {
+		if cmplx.Abs(z-r) < n.eps {
+			return i
+		}
+	}
+	return -1
+}
+
+func (n NewtonFractal) Frac(loc complex128) *Result {
+	z := loc
+	zPrev := cmplx.NaN()
+	o := n.newOrbit(z, 0)
+	for count := 0; count < n.MaxIterations-1; count++ {
+		// a custom criterion may cut the search short
+		if n.Escape != nil && n.Escape.Check(z, zPrev, n.Radius) != Iterating {
+			return o.done(&Result{
+				Z:          z,
+				ZPrev:      zPrev,
+				C:          loc,
+				Iterations: count,
+				Stats:      &Statistics{Root: -1},
+			})
+		}
+		if i := n.root(z); i >= 0 {
+			return o.done(&Result{
+				Z:          z,
+				C:          loc,
+				Iterations: count,
+				Converged:  true,
+				Stats:      &Statistics{Root: i},
+			})
+		}
+		z, zPrev = z-n.Relaxation*n.P.Eval(z)/n.dP.Eval(z), z
+		o.visit(z, count+1)
+	}
+
+	return o.done(&Result{
+		Z:          z,
+		C:          loc,
+		Iterations: n.MaxIterations - 1,
+		Stats:      &Statistics{Root: -1},
+	})
+}
+
+// NewtonBasinPlotter plots the results of a root-finding fractal such as
+// NewtonFractal. The integer part of a plotted value is the index of the root
+// to which a point converged, and the fractional part is the portion of the
+// maximum iteration count needed to get there. It is meant to be paired with
+// a BasinPalette.
+type NewtonBasinPlotter struct {
+	PlotterBase
+}
+
+func (p NewtonBasinPlotter) Plot(r *Result) float64 {
+	// basins describe convergent iterates, which PlotterBase.plot would
+	// plot by iteration count alone
+	if r.Stats == nil || r.Stats.Root < 0 {
+		return float64(p.MaxIterations - 1)
+	}
+	return float64(r.Stats.Root) + float64(r.Iterations)/float64(p.MaxIterations-1)
+}
+
+// BasinPalette colors the values produced by NewtonBasinPlotter. Each root is
+// given the color in Colors with the same index (modulo the number of colors),
+// which is then darkened according to the number of iterations it took to
+// reach the root. Shading, between 0 and 1, sets the strength of the effect.
+type BasinPalette struct {
+	Colors  color.Palette
+	Shading float64
+}
+
+func (p BasinPalette) SampleColor(val float64, maxIterations int) color.Color {
+	if isConvergent(val, maxIterations) || len(p.Colors) == 0 {
+		return black
+	}
+
+	root := int(val)
+	t := val - float64(root)
+	clr, _ := colorful.MakeColor(p.Colors[root%len(p.Colors)])
+	k := 1 - t*p.Shading
+	return colorful.Color{R: clr.R * k, G: clr.G * k, B: clr.B * k}.Clamped()
+}
@@ -0,0 +1,113 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac_test
+
+import (
+	"github.com/cfdwalrus/gofrac"
+	"github.com/lucasb-eyer/go-colorful"
+	"image/color"
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestNewtonFractal_Frac(t *testing.T) {
+	roots := []complex128{1, cmplx.Rect(1, 2*math.Pi/3), cmplx.Rect(1, -2*math.Pi/3)}
+	n := gofrac.NewNewtonFractal(1e-6, roots...)
+	n.SetMaxIterations(50)
+
+	// points near a root converge to it
+	for i, r := range roots {
+		got := n.Frac(r * 1.1)
+		if got.Stats.Root != i {
+			t.Errorf("%T: z_0 = %v: want: root %d, got: root %d", n, r*1.1, i, got.Stats.Root)
+		}
+	}
+
+	// the origin is a critical point of z^3 - 1, so Newton's method fails
+	if got := n.Frac(0); got.Stats.Root != -1 || got.Iterations != 49 {
+		t.Errorf("%T: z_0 = 0: want: root -1, 49 iterations, got: root %d, %d iterations", n, got.Stats.Root, got.Iterations)
+	}
+
+	// the same fractal built from coefficients agrees
+	fromCoeffs, err := gofrac.NewNewtonFractalFromCoefficients(1e-6, gofrac.Polynomial{-1, 0, 0, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromCoeffs.SetMaxIterations(50)
+	for _, z := range []complex128{0.3 + 0.9i, -2, 1.5 - 0.1i} {
+		want := roots[n.Frac(z).Stats.Root]
+		got := fromCoeffs.Roots[fromCoeffs.Frac(z).Stats.Root]
+		if cmplx.Abs(want-got) > 1e-6 {
+			t.Errorf("%T: z_0 = %v: want: %v, got: %v", fromCoeffs, z, want, got)
+		}
+	}
+}
+
+func TestNewtonBasinPlotter_Plot(t *testing.T) {
+	maxIt := 11
+	f := gofrac.FracData{MaxIterations: maxIt}
+	var p gofrac.NewtonBasinPlotter
+	p.SetFracData(&f)
+
+	tc := []struct {
+		r    gofrac.Result
+		want float64
+	}{
+		{gofrac.Result{Iterations: 0, Stats: &gofrac.Statistics{Root: 0}}, 0},
+		{gofrac.Result{Iterations: 5, Stats: &gofrac.Statistics{Root: 2}}, 2.5},
+		{gofrac.Result{Iterations: 4, Stats: &gofrac.Statistics{Root: -1}}, 10},
+		{gofrac.Result{Iterations: 10, Stats: &gofrac.Statistics{Root: -1}}, 10},
+	}
+	for _, tc := range tc {
+		if got := p.Plot(&tc.r); got != tc.want {
+			t.Errorf("%T: %v: want: %0.2f, got: %0.2f", p, tc.r, tc.want, got)
+		}
+	}
+}
+
+func TestNewtonBasinPlotter_Plot_Fractal(t *testing.T) {
+	roots := []complex128{1, -0.5 + 0.8660254037844386i, -0.5 - 0.8660254037844386i}
+	n := gofrac.NewNewtonFractal(1e-6, roots...)
+	_ = n.SetMaxIterations(50)
+	var p gofrac.NewtonBasinPlotter
+	p.SetFracData(n.Data())
+
+	// points near each root converge to it, and are plotted in its basin
+	for i, root := range roots {
+		r := n.Frac(root * 1.1)
+		got := p.Plot(r)
+		if !r.Converged || int(got) != i {
+			t.Errorf("%T: %v: want: basin %d, got: %0.2f", p, root*1.1, i, got)
+		}
+		if got == float64(i) {
+			t.Errorf("%T: %v: want: fractional part for %d iterations, got: %0.2f", p, root*1.1, r.Iterations, got)
+		}
+	}
+}
+
+func TestBasinPalette_SampleColor(t *testing.T) {
+	red := color.RGBA{0xff, 0x00, 0x00, 0xff}
+	blue := color.RGBA{0x00, 0x00, 0xff, 0xff}
+	p := gofrac.BasinPalette{Colors: color.Palette{red, blue}, Shading: 1}
+
+	maxIt := 100
+	tc := []testCase{
+		{val: 0.0, color: red},
+		{val: 1.0, color: blue},
+		{val: 2.0, color: red},
+		{val: 99.0, color: color.Black},
+	}
+	for _, tc := range tc {
+		cmp(t, p, tc, maxIt)
+	}
+
+	// slower convergence is darker
+	c1, _ := colorful.MakeColor(p.SampleColor(1.2, maxIt))
+	c2, _ := colorful.MakeColor(p.SampleColor(1.8, maxIt))
+	if c1.B <= c2.B {
+		t.Errorf("%T: want: %v brighter than %v", p, c1, c2)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac
+
+import "math/cmplx"
+
+// Nova is the Nova fractal, which adds a perturbation c to the relaxed
+// Newton's method for a polynomial p,
+// z_{n+1} = z_n - a p(z_n) / p'(z_n) + c, for all complex numbers c in the
+// domain. By default, iteration stops once successive iterates are within
+// Epsilon of each other or the iterate escapes the bailout radius. Escaping
+// iterates grow linearly rather than polynomially, so Nova has no degree for
+// smoothing.
+type Nova struct {
+	FracData
+
+	// P is the polynomial to which Newton's method is applied.
+	P Polynomial
+
+	// Relaxation is the factor a in the iteration above.
+	Relaxation complex128
+
+	// Z0 is the starting point of the iteration. It is 1 by default, which
+	// is a root of the classic choice of p, z^3 - 1.
+	Z0 complex128
+
+	dP Polynomial
+}
+
+// NewNova constructs a Nova struct for the polynomial p with a given bailout
+// radius and convergence threshold.
+func NewNova(radius float64, eps float64, p Polynomial) *Nova {
+	return &Nova{
+		FracData: FracData{
+			Radius:  radius,
+			Epsilon: eps,
+		},
+		P:          p,
+		Relaxation: 1,
+		Z0:         1,
+		dP:         p.Derivative(),
+	}
+}
+
+func (n Nova) Frac(loc complex128) *Result {
+	crit := n.criterion(CombinedCriterion{
+		ModulusCriterion{},
+		ConvergenceCriterion{Epsilon: n.Epsilon},
+	})
+	z, zPrev := n.Z0, cmplx.NaN()
+	count := 0
+	o := n.newOrbit(z, loc)
+	status := crit.Check(z, zPrev, n.Radius)
+	for status == Iterating {
+		z, zPrev = z-n.Relaxation*n.P.Eval(z)/n.dP.Eval(z)+loc, z
+		o.visit(z, count+1)
+		if count == n.MaxIterations-1 {
+			break
+		}
+		count++
+		status = crit.Check(z, zPrev, n.Radius)
+	}
+	return o.done(&Result{
+		Z:          z,
+		ZPrev:      zPrev,
+		C:          loc,
+		Iterations: count,
+		Converged:  status == Converged,
+	})
+}
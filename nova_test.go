@@ -0,0 +1,34 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac_test
+
+import (
+	"github.com/cfdwalrus/gofrac"
+	"math/cmplx"
+	"testing"
+)
+
+func TestNova_Frac(t *testing.T) {
+	p := gofrac.Polynomial{-1, 0, 0, 1} // z^3 - 1
+	n := gofrac.NewNova(100, 1e-9, p)
+	n.SetMaxIterations(100)
+
+	// with c = 0, z_0 = 1 is already a root, so a single step shows
+	// convergence
+	if got := n.Frac(0); !got.Converged || got.Iterations != 1 {
+		t.Errorf("%T: c = 0: want: convergence after 1 iteration, got: %v", n, *got)
+	}
+
+	// small perturbations converge to a fixed point of the perturbed map
+	c := 0.1 + 0.05i
+	got := n.Frac(c)
+	if !got.Converged {
+		t.Fatalf("%T: c = %v: want: convergence, got: %v", n, c, *got)
+	}
+	z := got.Z
+	if fixed := z - p.Eval(z)/p.Derivative().Eval(z) + c; cmplx.Abs(fixed-z) > 1e-6 {
+		t.Errorf("%T: c = %v: want: a fixed point, got: %v -> %v", n, c, z, fixed)
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac
+
+import (
+	"sync"
+	"time"
+)
+
+// Options configures the behavior of FracItOptions, RenderOptions, and
+// GetImageOptions. A nil *Options is equivalent to the zero value.
+type Options struct {
+	// Progress, if non-nil, is called every time a row of samples has been
+	// completed. Calls are serialized, but they are made from the worker
+	// goroutines, so Progress should return quickly.
+	Progress ProgressFunc
+
+	// Workers is the number of goroutines performing the calculation. If it
+	// is less than one, runtime.NumCPU() workers are used. It is ignored if
+	// Pool is set.
+	Workers int
+
+	// Schedule determines how the samples are divided into jobs for the
+	// workers. The default is ScheduleRows.
+	Schedule Schedule
+
+	// TileSize is the side length, in samples, of the tiles used by
+	// ScheduleTiles. If it is less than one, a default of 64 is used.
+	TileSize int
+
+	// Pool, if non-nil, runs the jobs on a shared set of workers instead of
+	// starting new goroutines.
+	Pool *Pool
+
+	// Subdivide makes FracItOptions use the Mariani-Silver algorithm: each
+	// tile of TileSize samples is recursively divided into rectangles, only
+	// whose borders are calculated, and rectangles whose borders share a
+	// single escape time are filled in with it. For connected sets such as
+	// the Mandelbrot set or a connected Julia set, the escape times match
+	// those of the full calculation almost everywhere, but they can differ
+	// where features thinner than a sample, such as filaments, cross a
+	// rectangle between the samples of its border. The filled-in Results
+	// carry nothing but Iterations and Converged, so Subdivide only suits
+	// plotters that depend on the escape time alone. Schedule is ignored.
+	Subdivide bool
+
+	// Supersample configures the anti-aliasing of GetImageOptions. It is
+	// disabled by default.
+	Supersample Supersampling
+}
+
+// ProgressFunc receives progress reports from a long-running calculation.
+type ProgressFunc func(p Progress)
+
+// Stage identifies the part of the image generation pipeline a Progress
+// report belongs to.
+type Stage int
+
+const (
+	// StageFrac is the iteration of the fractal calculation (FracIt).
+	StageFrac Stage = iota
+	// StageRender is the mapping of results to colors (Render).
+	StageRender
+)
+
+func (s Stage) String() string {
+	switch s {
+	case StageFrac:
+		return "frac"
+	case StageRender:
+		return "render"
+	}
+	return "unknown"
+}
+
+// Progress is a snapshot of how far a calculation has come.
+type Progress struct {
+	// Stage is the part of the pipeline being reported on.
+	Stage Stage
+
+	// Completed and Total give the number of finished rows and the total
+	// number of rows, respectively.
+	Completed, Total int
+
+	// Elapsed is the time since the stage began.
+	Elapsed time.Duration
+
+	// ETA is the estimated time until the stage is finished, extrapolated
+	// from the rate at which rows have been completed so far.
+	ETA time.Duration
+}
+
+// Fraction returns the completed portion of a stage as a number in [0, 1].
+func (p Progress) Fraction() float64 {
+	if p.Total == 0 {
+		return 1
+	}
+	return float64(p.Completed) / float64(p.Total)
+}
+
+// progressTracker counts completed rows and forwards reports to a
+// ProgressFunc.
+type progressTracker struct {
+	mu        sync.Mutex
+	fn        ProgressFunc
+	stage     Stage
+	start     time.Time
+	remaining []int
+	completed int
+}
+
+// newProgressTracker returns a tracker for a stage of rows by cols samples, or
+// nil if opts doesn't ask for progress reports.
+func newProgressTracker(opts *Options, stage Stage, rows int, cols int) *progressTracker {
+	if opts == nil || opts.Progress == nil {
+		return nil
+	}
+
+	remaining := make([]int, rows)
+	for row := range remaining {
+		remaining[row] = cols
+	}
+	return &progressTracker{
+		fn:        opts.Progress,
+		stage:     stage,
+		start:     time.Now(),
+		remaining: remaining,
+	}
+}
+
+// segmentDone records the completion of n samples of a row and sends a report
+// if that completes the row. It is safe to call on a nil tracker.
+func (t *progressTracker) segmentDone(row int, n int) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.remaining[row] -= n
+	if t.remaining[row] > 0 {
+		return
+	}
+
+	t.completed++
+	total := len(t.remaining)
+	elapsed := time.Since(t.start)
+	eta := time.Duration(float64(elapsed) * float64(total-t.completed) / float64(t.completed))
+	t.fn(Progress{
+		Stage:     t.stage,
+		Completed: t.completed,
+		Total:     total,
+		Elapsed:   elapsed,
+		ETA:       eta,
+	})
+}
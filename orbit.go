@@ -0,0 +1,101 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// orbit collects the statistics of an orbit that are requested in FracData in
+// addition to its final iterate, e.g. the distances to orbit traps. A nil
+// *orbit collects nothing, so a fractal can report every iterate to it
+// without checking what was requested first.
+type orbit struct {
+	// c is the parameter of the iteration, and zPrev and zPrev2 are the two
+	// most recently visited iterates.
+	c, zPrev, zPrev2 complex128
+
+	traps         []OrbitTrap
+	trapDistance  float64
+	trapIteration int
+	trapPoint     complex128
+	trapIndex     int
+
+	averager Averager
+	sum      float64
+	terms    int
+	last     float64
+}
+
+// needsOrbit reports whether f asks for more than the escape time of an
+// orbit, in which case its iterates can't be skipped.
+func (f *FracData) needsOrbit() bool {
+	return len(f.Traps) > 0 || f.Average != nil || f.Cycles
+}
+
+// newOrbit returns an orbit starting at z with parameter c that collects the
+// statistics requested in f, or nil if there are none.
+func (f *FracData) newOrbit(z complex128, c complex128) *orbit {
+	if len(f.Traps) == 0 && f.Average == nil {
+		return nil
+	}
+	return &orbit{
+		c:            c,
+		zPrev:        z,
+		zPrev2:       cmplx.NaN(),
+		traps:        f.Traps,
+		trapDistance: math.Inf(1),
+		trapIndex:    -1,
+		averager:     f.Average,
+		last:         math.NaN(),
+	}
+}
+
+// visit records the nth iterate z of an orbit.
+func (o *orbit) visit(z complex128, n int) {
+	if o == nil {
+		return
+	}
+	for i, t := range o.traps {
+		if d := t.Distance(z); d < o.trapDistance {
+			o.trapDistance = d
+			o.trapIteration = n
+			o.trapPoint = z
+			o.trapIndex = i
+		}
+	}
+	if o.averager != nil {
+		// terms that are undefined for an iterate are left out
+		o.last = o.averager.Term(z, o.zPrev, o.zPrev2, o.c)
+		if !math.IsNaN(o.last) {
+			o.sum += o.last
+			o.terms++
+		}
+	}
+	o.zPrev, o.zPrev2 = z, o.zPrev
+}
+
+// done stores the collected statistics in r and returns it.
+func (o *orbit) done(r *Result) *Result {
+	if o == nil {
+		return r
+	}
+	s := r.stats()
+	if o.traps != nil {
+		s.TrapDistance = o.trapDistance
+		s.TrapIteration = o.trapIteration
+		s.TrapPoint = o.trapPoint
+		s.TrapIndex = o.trapIndex
+	}
+	if o.averager != nil {
+		s.Average = o.sum / float64(o.terms)
+		s.AveragePrev = s.Average
+		if !math.IsNaN(o.last) && o.terms > 1 {
+			s.AveragePrev = (o.sum - o.last) / float64(o.terms-1)
+		}
+	}
+	return r
+}
@@ -0,0 +1,49 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac
+
+// Phoenix is the Phoenix fractal discovered by Shigehiro Ushiki, which results
+// from iterating z_{n+1} = z_n^2 + C + P z_{n-1} for all complex numbers z_0
+// in the domain, with z_{-1} = 0. The classic image uses C = 0.5667 and
+// P = -0.5.
+type Phoenix struct {
+	Quadratic
+	C complex128
+	P complex128
+}
+
+// NewPhoenix constructs a Phoenix struct with a given bailout radius and
+// complex parameters c and p.
+func NewPhoenix(radius float64, c complex128, p complex128) *Phoenix {
+	return &Phoenix{
+		Quadratic: NewQuadratic(radius),
+		C:         c,
+		P:         p,
+	}
+}
+
+func (ph Phoenix) Frac(loc complex128) *Result {
+	crit := ph.criterion(ModulusCriterion{})
+	z, zPrev := loc, complex128(0)
+	count := 0
+	o := ph.newOrbit(z, ph.C)
+	status := crit.Check(z, zPrev, ph.Radius)
+	for status == Iterating {
+		z, zPrev = z*z+ph.C+ph.P*zPrev, z
+		o.visit(z, count+1)
+		if count == ph.MaxIterations-1 {
+			break
+		}
+		count++
+		status = crit.Check(z, zPrev, ph.Radius)
+	}
+	return o.done(&Result{
+		Z:          z,
+		ZPrev:      zPrev,
+		C:          ph.C,
+		Iterations: count,
+		Converged:  status == Converged,
+	})
+}
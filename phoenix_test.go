@@ -0,0 +1,33 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac_test
+
+import (
+	"github.com/cfdwalrus/gofrac"
+	"testing"
+)
+
+func TestPhoenix_Frac(t *testing.T) {
+	c, p := 0.5667+0i, -0.5+0i
+	f := gofrac.NewPhoenix(100, c, p)
+	z0 := 0.1 + 0.2i
+
+	// z_{-1} = 0, so the first iteration is a plain quadratic one, and the
+	// second one feeds back z_0
+	z1 := z0*z0 + c
+	z2 := z1*z1 + c + p*z0
+
+	f.SetMaxIterations(2)
+	got := f.Frac(z0)
+	if got.Z != z2 || got.ZPrev != z1 || got.Iterations != 1 {
+		t.Errorf("%T: want: Z = %v, ZPrev = %v, 1 iteration, got: Z = %v, ZPrev = %v, %d iterations", f, z2, z1, got.Z, got.ZPrev, got.Iterations)
+	}
+
+	// far away points escape immediately
+	f.SetMaxIterations(100)
+	if got := f.Frac(1000); got.Iterations != 0 {
+		t.Errorf("%T: want: 0 iterations, got: %d", f, got.Iterations)
+	}
+}
@@ -20,12 +20,45 @@ type Plotter interface {
 	SetFracData(fd *FracData)
 }
 
+// Configurer is implemented by Plotters that need more from a fractal
+// calculation than the Results it produces by default, or that depend on the
+// domain being plotted. GetImage and its variants call Configure with the
+// FracData of the fractal and the domain before the calculation starts, and
+// undo its changes to the features of the FracData (Derivative, Traps,
+// Average, Cycles and Periodicity) once the image is done. d may be nil if
+// the domain can't be read as a DomainReader.
+type Configurer interface {
+	Configure(fd *FracData, d DomainReader)
+}
+
+// configure calls Configure on plotter if it is a Configurer. It returns a
+// function that restores the features of fd that Configure may have changed,
+// so that they only apply to a single image.
+func configure(plotter Plotter, fd *FracData, d DomainReader) (restore func()) {
+	c, ok := plotter.(Configurer)
+	if !ok {
+		return func() {}
+	}
+
+	saved := *fd
+	c.Configure(fd, d)
+	return func() {
+		fd.Derivative = saved.Derivative
+		fd.Traps = saved.Traps
+		fd.Average = saved.Average
+		fd.Cycles = saved.Cycles
+		fd.Periodicity = saved.Periodicity
+	}
+}
+
 type PlotterBase struct {
 	FracData
 }
 
 func (pb *PlotterBase) plot(r *Result, pFunc func(r *Result) float64) float64 {
-	if r.Iterations == pb.MaxIterations-1 {
+	// the plotting functions describe escaping iterates, so convergent ones
+	// are simply plotted by iteration count
+	if r.Iterations == pb.MaxIterations-1 || r.Converged {
 		return float64(r.Iterations)
 	}
 	return pFunc(r)
@@ -48,11 +81,18 @@ func (p EscapeTimePlotter) Plot(r *Result) float64 {
 
 func smooth(val float64, z complex128, d FracDataGetter) float64 {
 	mod := cmplx.Abs(z)
-	if mod == 0 {
+	if mod == 0 || math.IsInf(mod, 0) || math.IsNaN(mod) {
 		return val
 	}
+	// the potential is only meaningful for iterates that grow like |z|^d
+	// with d > 1
 	lgBase := d.Data().logDegreeInv
-	return val + 1 - math.Log(math.Log(mod))*lgBase
+	if math.IsNaN(lgBase) || lgBase <= 0 || math.IsInf(lgBase, 0) {
+		return val
+	}
+	// far beyond the bailout radius, e.g. near the point at infinity, the
+	// potential would drop below the first iteration band
+	return math.Max(0, val+1-math.Log(math.Log(mod))*lgBase)
 }
 
 // SmoothedEscapeTimePlotter maps a Result to a value in a way analogous to
@@ -102,3 +142,75 @@ func (p PhasePlotter) Plot(r *Result) float64 {
 		return float64(p.MaxIterations-1) * (cmplx.Phase(r.Z) + math.Pi) / (2 * math.Pi)
 	})
 }
+
+// DistanceEstimatorPlotter plots an escaped iterate according to its estimated
+// distance to the boundary of the set, |z| log|z| / |dz|, measured in pixels.
+// This draws the filaments of a fractal as crisp lines regardless of how many
+// iterations it takes to resolve them.
+//
+// The estimate requires derivative tracking, which Configure enables. Results
+// without a derivative (e.g., from fractals that don't support it) are
+// plotted as if they were far from the set.
+type DistanceEstimatorPlotter struct {
+	PlotterBase
+
+	// PixelSize is the distance between neighboring samples. If it is zero,
+	// it is measured from the domain by Configure.
+	PixelSize float64
+
+	// Thickness is the width, in pixels, of the boundary lines. Iterates
+	// closer than that to the set are plotted as part of it.
+	Thickness float64
+
+	// Scale is the distance, in pixels, over which the palette is spread.
+	// Nearby iterates are plotted at the top end of the palette, like iterates
+	// with high escape times. If it is zero, it defaults to 4 pixels.
+	Scale float64
+
+	pixelSize float64
+}
+
+const defaultDEScale = 4
+
+func (p *DistanceEstimatorPlotter) Configure(fd *FracData, d DomainReader) {
+	fd.SetDerivative(true)
+	if d != nil {
+		p.pixelSize = PixelSize(d)
+	}
+}
+
+// Distance returns the estimated distance of r to the boundary of the set, in
+// units of the complex plane.
+func (p DistanceEstimatorPlotter) Distance(r *Result) float64 {
+	dz := cmplx.Abs(r.DZ)
+	if dz == 0 {
+		return math.Inf(1)
+	}
+	mod := cmplx.Abs(r.Z)
+	if math.IsInf(mod, 0) {
+		return math.Inf(1)
+	}
+	return mod * math.Log(mod) / dz
+}
+
+func (p DistanceEstimatorPlotter) Plot(r *Result) float64 {
+	return p.plot(r, func(r *Result) float64 {
+		px := p.PixelSize
+		if px == 0 {
+			px = p.pixelSize
+		}
+		scale := p.Scale
+		if scale == 0 {
+			scale = defaultDEScale
+		}
+
+		t := p.Distance(r)
+		if px > 0 {
+			t /= px
+		}
+		if t < p.Thickness {
+			return float64(p.MaxIterations - 1)
+		}
+		return float64(p.MaxIterations-2) * (1 - math.Tanh((t-p.Thickness)/scale))
+	})
+}
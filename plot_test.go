@@ -7,6 +7,7 @@ package gofrac_test
 import (
 	"github.com/cfdwalrus/gofrac"
 	"math"
+	"math/cmplx"
 	"testing"
 )
 
@@ -83,3 +84,93 @@ func TestNormalizedEscapeTimePlotter_Plot(t *testing.T) {
 func TestNormalizedSmoothedEscapeTimePlotter_Plot(t *testing.T) {
 
 }
+
+func TestSmoothedEscapeTimePlotter_Plot_Converged(t *testing.T) {
+	f := gofrac.FracData{Radius: 4, MaxIterations: 10}
+	f.SetDegree(2)
+
+	var p gofrac.SmoothedEscapeTimePlotter
+	p.SetFracData(&f)
+
+	// iterates converging to a finite attractor are plotted by iteration
+	// count, since their potential isn't defined
+	converged := gofrac.Result{Z: 1, Iterations: 3, Converged: true}
+	want := 3.0
+	if got := p.Plot(&converged); got != want {
+		t.Errorf("%T: want: %0.2f, got: %0.2f", p, want, got)
+	}
+}
+
+func TestSmoothedEscapeTimePlotter_Plot_Infinite(t *testing.T) {
+	f := gofrac.FracData{Radius: 4, MaxIterations: 10}
+	f.SetDegree(2)
+
+	var p gofrac.SmoothedEscapeTimePlotter
+	p.SetFracData(&f)
+
+	// iterates far beyond the bailout radius, up to infinity itself, stay
+	// within the range of the palettes
+	for _, z := range []complex128{1e200, cmplx.Inf()} {
+		r := gofrac.Result{Z: z, Iterations: 1}
+		if got := p.Plot(&r); !(got >= 0 && got <= 1) {
+			t.Errorf("%T: Z = %v: want: value in [0, 1], got: %0.2f", p, z, got)
+		}
+	}
+}
+
+func TestDistanceEstimatorPlotter_Plot(t *testing.T) {
+	f := gofrac.FracData{Radius: 1000, MaxIterations: 100}
+	f.SetDegree(2)
+
+	p := gofrac.DistanceEstimatorPlotter{PixelSize: 0.01, Thickness: 1}
+	p.SetFracData(&f)
+
+	// |z| log|z| / |dz| = e / (100 e) = 0.01, i.e. one pixel
+	z := complex(math.E, 0)
+	near := gofrac.Result{Z: z, DZ: complex(100*math.E, 0), Iterations: 10}
+	if got := p.Distance(&near); math.Abs(got-0.01) > 1e-12 {
+		t.Errorf("%T: want: %0.5f, got: %0.5f", p, 0.01, got)
+	}
+
+	// iterates within the line thickness are plotted as part of the set
+	near.DZ *= 2
+	if got := p.Plot(&near); got != 99 {
+		t.Errorf("%T: want: %0.2f, got: %0.2f", p, 99.0, got)
+	}
+
+	// distant iterates are plotted at the bottom of the palette, nearer ones
+	// higher up
+	far := gofrac.Result{Z: z, DZ: complex(math.E, 0), Iterations: 10}
+	mid := gofrac.Result{Z: z, DZ: complex(20*math.E, 0), Iterations: 10}
+	if pf, pm := p.Plot(&far), p.Plot(&mid); !(pf < 0.01 && pm > pf && pm < 98) {
+		t.Errorf("%T: want: far < mid < 98, got: far = %0.2f, mid = %0.2f", p, pf, pm)
+	}
+
+	// without a derivative, an iterate is treated as far away
+	none := gofrac.Result{Z: z, Iterations: 10}
+	if got := p.Plot(&none); got != 0 {
+		t.Errorf("%T: want: %0.2f, got: %0.2f", p, 0.0, got)
+	}
+}
+
+func TestDistanceEstimatorPlotter_Configure(t *testing.T) {
+	m := gofrac.NewMandelbrot(1000)
+	d, _ := gofrac.NewDomain(-2, -1, 1, 1, 30, 20)
+	p := &gofrac.DistanceEstimatorPlotter{}
+	_, err := gofrac.GetImage(m, d, p, gofrac.SpectralPalette{Sweep: 360}, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.Derivative {
+		t.Errorf("%T: want: derivative tracking enabled, got: disabled", p)
+	}
+
+	// the fractal itself is left as it was, and can be plotted without
+	// derivatives
+	if m.Derivative {
+		t.Errorf("%T: want: derivative tracking disabled after the image, got: enabled", m)
+	}
+	if r := m.Frac(0); r.DZ != 0 {
+		t.Errorf("%T: want: DZ = 0, got: %v", m, r.DZ)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac
+
+import (
+	"errors"
+	"math/cmplx"
+)
+
+// Polynomial is a complex polynomial whose coefficients are stored in order of
+// increasing degree. That is, p(z) = p[0] + p[1]z + p[2]z^2 + ...
+type Polynomial []complex128
+
+// NewPolynomialFromRoots constructs the monic polynomial whose roots are given
+// by roots.
+func NewPolynomialFromRoots(roots ...complex128) Polynomial {
+	p := Polynomial{1}
+	for _, root := range roots {
+		// multiply by (z - root)
+		next := make(Polynomial, len(p)+1)
+		for i, a := range p {
+			next[i+1] += a
+			next[i] -= a * root
+		}
+		p = next
+	}
+	return p
+}
+
+// Degree returns the degree of p, ignoring any vanishing leading
+// coefficients. The zero polynomial has degree -1.
+func (p Polynomial) Degree() int {
+	for n := len(p) - 1; n >= 0; n-- {
+		if p[n] != 0 {
+			return n
+		}
+	}
+	return -1
+}
+
+// Eval evaluates p at z using Horner's method.
+func (p Polynomial) Eval(z complex128) complex128 {
+	var sum complex128
+	for n := len(p) - 1; n >= 0; n-- {
+		sum = sum*z + p[n]
+	}
+	return sum
+}
+
+// Derivative returns the derivative of p.
+func (p Polynomial) Derivative() Polynomial {
+	if len(p) < 2 {
+		return Polynomial{}
+	}
+	d := make(Polynomial, len(p)-1)
+	for n := 1; n < len(p); n++ {
+		d[n-1] = complex(float64(n), 0) * p[n]
+	}
+	return d
+}
+
+// Taylor returns the first n+1 Taylor coefficients of p about z, i.e.
+// p^{(k)}(z) / k! for k = 0, 1, ..., n. These are the coefficients of the
+// polynomial p(z + h) in h.
+func (p Polynomial) Taylor(z complex128, n int) []complex128 {
+	t := make([]complex128, len(p))
+	copy(t, p)
+	for k := 0; k < len(t)-1; k++ {
+		for i := len(t) - 2; i >= k; i-- {
+			t[i] += z * t[i+1]
+		}
+	}
+
+	coeffs := make([]complex128, n+1)
+	copy(coeffs, t)
+	return coeffs
+}
+
+const (
+	rootTolerance     = 1e-14
+	maxRootIterations = 1000
+)
+
+// Roots finds all of the roots of p, repeated according to their
+// multiplicity, with the Durand-Kerner method. An error is returned if p is
+// constant or the method fails to converge.
+func (p Polynomial) Roots() ([]complex128, error) {
+	n := p.Degree()
+	if n < 1 {
+		return nil, errors.New("gofrac: a constant polynomial has no roots to find")
+	}
+
+	// work with the monic version of p
+	monic := make(Polynomial, n+1)
+	for i := range monic {
+		monic[i] = p[i] / p[n]
+	}
+
+	roots := make([]complex128, n)
+	seed := complex(0.4, 0.9)
+	roots[0] = 1
+	for i := 1; i < n; i++ {
+		roots[i] = roots[i-1] * seed
+	}
+
+	for it := 0; it < maxRootIterations; it++ {
+		maxStep := 0.0
+		for i, r := range roots {
+			denom := complex128(1)
+			for j, s := range roots {
+				if i != j {
+					denom *= r - s
+				}
+			}
+			step := monic.Eval(r) / denom
+			roots[i] = r - step
+			if a := cmplx.Abs(step); a > maxStep {
+				maxStep = a
+			}
+		}
+		if maxStep < rootTolerance {
+			return roots, nil
+		}
+	}
+
+	// repeated roots converge slowly, so accept a result that is good enough
+	for _, r := range roots {
+		if cmplx.Abs(monic.Eval(r)) > 1e-9 {
+			return nil, errors.New("gofrac: failed to find the roots of the polynomial")
+		}
+	}
+	return roots, nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac_test
+
+import (
+	"github.com/cfdwalrus/gofrac"
+	"math/cmplx"
+	"testing"
+)
+
+func TestNewPolynomialFromRoots(t *testing.T) {
+	// (z - 1)(z + 2i) = z^2 + (2i - 1)z - 2i
+	p := gofrac.NewPolynomialFromRoots(1, -2i)
+	want := gofrac.Polynomial{-2i, -1 + 2i, 1}
+	if len(p) != len(want) {
+		t.Fatalf("%T: want: %v, got: %v", p, want, p)
+	}
+	for i := range want {
+		if p[i] != want[i] {
+			t.Errorf("%T: want: %v, got: %v", p, want, p)
+		}
+	}
+}
+
+func TestPolynomial_Eval(t *testing.T) {
+	p := gofrac.Polynomial{1, 0, 3, 2} // 2z^3 + 3z^2 + 1
+	d := p.Derivative()                // 6z^2 + 6z
+
+	for _, z := range []complex128{0, 1, -1, 1i, 0.5 - 2i} {
+		if want, got := 2*z*z*z+3*z*z+1, p.Eval(z); cmplx.Abs(want-got) > 1e-12 {
+			t.Errorf("%T: p(%v): want: %v, got: %v", p, z, want, got)
+		}
+		if want, got := 6*z*z+6*z, d.Eval(z); cmplx.Abs(want-got) > 1e-12 {
+			t.Errorf("%T: p'(%v): want: %v, got: %v", p, z, want, got)
+		}
+	}
+}
+
+func TestPolynomial_Degree(t *testing.T) {
+	tc := []struct {
+		p    gofrac.Polynomial
+		want int
+	}{
+		{gofrac.Polynomial{}, -1},
+		{gofrac.Polynomial{0, 0}, -1},
+		{gofrac.Polynomial{5}, 0},
+		{gofrac.Polynomial{1, 2, 0, 0}, 1},
+		{gofrac.Polynomial{-1, 0, 0, 1}, 3},
+	}
+	for _, tc := range tc {
+		if got := tc.p.Degree(); got != tc.want {
+			t.Errorf("%T: %v: want: %d, got: %d", tc.p, tc.p, tc.want, got)
+		}
+	}
+}
+
+func TestPolynomial_Roots(t *testing.T) {
+	want := []complex128{1, -0.5 + 2i, -3, 2.5i}
+	p := gofrac.NewPolynomialFromRoots(want...)
+
+	got, err := p.Roots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("%T: want: %d roots, got: %d", p, len(want), len(got))
+	}
+	for _, w := range want {
+		found := false
+		for _, g := range got {
+			if cmplx.Abs(w-g) < 1e-9 {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("%T: want: root %v, got: %v", p, w, got)
+		}
+	}
+
+	if _, err := (gofrac.Polynomial{3}).Roots(); err == nil {
+		t.Errorf("Error not caught for constant polynomial")
+	}
+}
+
+func TestPolynomial_Taylor(t *testing.T) {
+	p := gofrac.Polynomial{1, 0, 3, 2} // 2z^3 + 3z^2 + 1
+	z := 0.5 - 1i
+
+	want := []complex128{2*z*z*z + 3*z*z + 1, 6*z*z + 6*z, 6*z + 3, 2, 0}
+	got := p.Taylor(z, 4)
+	for k := range want {
+		if cmplx.Abs(want[k]-got[k]) > 1e-12 {
+			t.Errorf("%T: a_%d: want: %v, got: %v", p, k, want[k], got[k])
+		}
+	}
+}
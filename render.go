@@ -5,9 +5,8 @@
 package gofrac
 
 import (
+	"context"
 	"image/color"
-	"runtime"
-	"sync"
 )
 
 // bitmap stores a 2D field of color.Color that can be used to generate images.
@@ -26,33 +25,32 @@ func NewBitmap(r int, c int) bitmap {
 // Render combines the fractal iteration results with a plotting method and
 // generates a bitmap according to the color palette provided.
 func Render(results *Results, plotter Plotter, palette ColorSampler) bitmap {
-	rows, cols := results.Dimensions()
-	bitmap := NewBitmap(rows, cols)
-
-	rowJobs := make(chan int, rows)
-
-	numWorkers := runtime.NumCPU()
-	wg := sync.WaitGroup{}
-	for worker := 0; worker < numWorkers; worker++ {
-		wg.Add(1)
-		go func() {
-			for row := range rowJobs {
-				for col := 0; col < cols; col++ {
-					result := results.At(row, col)
-					val := plotter.Plot(result)
-					bitmap[row][col] = palette.SampleColor(val, results.maxIterations)
-				}
-			}
-			wg.Done()
-		}()
-	}
+	bitmap, _ := RenderContext(context.Background(), results, plotter, palette)
+	return bitmap
+}
 
-	for row := 0; row < rows; row++ {
-		rowJobs <- row
-	}
+// RenderContext is like Render, but stops its workers as soon as ctx is
+// cancelled. In that case, the partially rendered bitmap is returned along
+// with ctx.Err(). Entries that were never reached are left nil.
+func RenderContext(ctx context.Context, results *Results, plotter Plotter, palette ColorSampler) (bitmap, error) {
+	return RenderOptions(ctx, results, plotter, palette, nil)
+}
 
-	close(rowJobs)
-	wg.Wait()
+// RenderOptions is like RenderContext, but its behavior can be further
+// configured with opts, which may be nil.
+func RenderOptions(ctx context.Context, results *Results, plotter Plotter, palette ColorSampler, opts *Options) (bitmap, error) {
+	rows, cols := results.Dimensions()
+	bitmap := NewBitmap(rows, cols)
 
-	return bitmap
+	progress := newProgressTracker(opts, StageRender, rows, cols)
+	err := forEachJob(ctx, rows, cols, opts, progress, func(row, col0, col1 int) error {
+		for col := col0; col < col1; col++ {
+			result := results.At(row, col)
+			val := plotter.Plot(result)
+			bitmap[row][col] = palette.SampleColor(val, results.maxIterations)
+		}
+		return nil
+	})
+
+	return bitmap, err
 }
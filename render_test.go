@@ -5,6 +5,7 @@
 package gofrac_test
 
 import (
+	"context"
 	"github.com/cfdwalrus/gofrac"
 	"image/color"
 	"testing"
@@ -63,3 +64,24 @@ func TestRender(t *testing.T) {
 		}
 	}
 }
+
+func TestRenderContext(t *testing.T) {
+	fakeResults := gofrac.NewResults(4, 4, 4)
+	mockSampleColor = func(float64, int) color.Color {
+		return color.White
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	bitmap, err := gofrac.RenderContext(ctx, &fakeResults, &fakePlotter{}, fakePalette{})
+	if err != context.Canceled {
+		t.Errorf("RenderContext: want: err == %v, got: err == %v", context.Canceled, err)
+	}
+	for _, row := range bitmap {
+		for _, got := range row {
+			if got != nil {
+				t.Errorf("RenderContext: want: nil, got: %v", got)
+			}
+		}
+	}
+}
@@ -4,23 +4,102 @@
 
 package gofrac
 
+import "sync"
+
 type Result struct {
 	Z          complex128
 	C          complex128
 	Iterations int
 	NFactor    float64
+
+	// ZPrev is the iterate preceding Z. It is only set by fractals whose
+	// iteration depends on it (e.g., Phoenix) or that test for convergence.
+	ZPrev complex128
+
+	// Converged is true if the iterates approached a finite attractor to
+	// within the convergence threshold rather than escaping or running out
+	// of iterations.
+	Converged bool
+
+	// Glitched is true if a perturbation-based calculation (e.g.,
+	// DeepMandelbrot) detected a loss of precision that it couldn't
+	// correct.
+	Glitched bool
+
+	// DZ is the derivative of Z with respect to the plotted variable: dz/dc
+	// for parameter-plane fractals (e.g., Mandelbrot) and dz/dz_0 for
+	// dynamical-plane fractals (e.g., JuliaQ). It is only set when
+	// derivative tracking is enabled in FracData.
+	DZ complex128
+
+	// Stats holds the optional statistics of the orbit, or is nil if the
+	// fractal collected none. They are kept apart from the Result so that
+	// plain calculations don't pay for them.
+	Stats *Statistics
+}
+
+// Statistics holds the statistics of an orbit that fractals only collect on
+// request, along with the root reached by root-finding fractals.
+type Statistics struct {
+	// TrapDistance is the closest approach of the orbit to the orbit traps
+	// in FracData, TrapIteration is the index n of the iterate z_n that came
+	// closest, TrapPoint is that iterate, and TrapIndex is the index of the
+	// trap it came closest to, or -1 if no trap caught the orbit. They are
+	// only set when FracData has orbit traps.
+	TrapDistance  float64
+	TrapIteration int
+	TrapPoint     complex128
+	TrapIndex     int
+
+	// Average is the average of the terms computed by the Averager in
+	// FracData over the orbit, and AveragePrev is the same average without
+	// the term of the final iterate. They are only set when FracData has an
+	// Averager.
+	Average     float64
+	AveragePrev float64
+
+	// Period is the period of the attracting cycle approached by a bounded
+	// orbit, or 0 if none was found, and Multiplier is the multiplier of that
+	// cycle. InteriorDistance is the estimated distance of a point inside the
+	// Mandelbrot set to its boundary. They are only set when cycle detection
+	// is enabled in FracData.
+	Period           int
+	Multiplier       complex128
+	InteriorDistance float64
+
+	// Root is the index of the root to which the iterates of a root-finding
+	// fractal converged, or -1 if they didn't converge. It is only set by
+	// such fractals (e.g., NewtonFractal).
+	Root int
+}
+
+// stats returns the Statistics of r, which are allocated on first use.
+func (r *Result) stats() *Statistics {
+	if r.Stats == nil {
+		r.Stats = &Statistics{}
+	}
+	return r.Stats
 }
 
 // Results is a 2D slice of Result objects.
 type Results struct {
 	results       [][]Result
 	maxIterations int
+
+	// stats is the side table of the Statistics of the Results, whose rows
+	// are only allocated once a Result in them has any
+	stats   [][]Statistics
+	statsMu *sync.Mutex
 }
 
 // NewResults constructs a 2D slice of Result objects, with outer and inner
 // dimensions of rows and cols, respectively.
 func NewResults(rows int, cols int, maxIterations int) Results {
-	r := Results{maxIterations: maxIterations}
+	r := Results{
+		maxIterations: maxIterations,
+		stats:         make([][]Statistics, rows),
+		statsMu:       &sync.Mutex{},
+	}
 	r.results = make([][]Result, rows)
 	for row := range r.results {
 		r.results[row] = make([]Result, cols)
@@ -36,6 +115,31 @@ func (r Results) SetResult(row int, col int, z complex128, c complex128, iterati
 	r.results[row][col].Iterations = iterations
 }
 
+// set copies every field of result except NFactor, which is calculated by
+// Done, into the Result located at the coordinates (row, col). Its
+// Statistics, if any, are copied into the side table.
+func (r Results) set(row int, col int, result *Result) {
+	nFactor := r.results[row][col].NFactor
+	r.results[row][col] = *result
+	r.results[row][col].NFactor = nFactor
+	if result.Stats != nil {
+		stats := r.statsRow(row)
+		stats[col] = *result.Stats
+		r.results[row][col].Stats = &stats[col]
+	}
+}
+
+// statsRow returns the row of the side table of Statistics, allocating it if
+// necessary. Rows may be shared by several workers, hence the lock.
+func (r Results) statsRow(row int) []Statistics {
+	r.statsMu.Lock()
+	defer r.statsMu.Unlock()
+	if r.stats[row] == nil {
+		r.stats[row] = make([]Statistics, len(r.results[row]))
+	}
+	return r.stats[row]
+}
+
 // At retrieves the Result at the coordinates (row, col).
 func (r Results) At(row int, col int) *Result {
 	return &r.results[row][col]
@@ -73,26 +177,33 @@ func calculateAccumulatedHistogram(r Results) (hist []int) {
 }
 
 func setNFactors(r Results, hist []int) {
-	invTotal := 1.0
+	scale := nFactorScale(hist, r.maxIterations)
+	for row := range r.results {
+		for col := range r.results[row] {
+			setNFactor(r.At(row, col), hist, scale, r.maxIterations)
+		}
+	}
+}
 
-	if r.maxIterations > 1 {
+// nFactorScale returns the factor that turns the accumulated histogram hist
+// into NFactors.
+func nFactorScale(hist []int, maxIterations int) float64 {
+	if maxIterations > 1 {
 		lastDivergent := hist[len(hist)-2]
 		// non-degenerate case
 		if lastDivergent > 0 {
-			invTotal = 1.0 / float64(lastDivergent)
+			return 1.0 / float64(lastDivergent)
 		}
 	}
+	return 1.0
+}
 
-	for row := range r.results {
-		for col := range r.results[row] {
-			result := r.At(row, col)
-			i := result.Iterations
-
-			// only escaped results are normalized
-			if i < r.maxIterations-1 {
-				result.NFactor = float64(hist[i]) * invTotal
-			}
-		}
+// setNFactor normalizes result according to the accumulated histogram hist,
+// whose scale is given by nFactorScale.
+func setNFactor(result *Result, hist []int, scale float64, maxIterations int) {
+	// only escaped results are normalized
+	if i := result.Iterations; i < maxIterations-1 {
+		result.NFactor = float64(hist[i]) * scale
 	}
 }
 
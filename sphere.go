@@ -0,0 +1,141 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac
+
+import (
+	"errors"
+	"math"
+	"math/cmplx"
+)
+
+// SphereDomain samples the whole complex plane, including the point at
+// infinity, as an equirectangular panorama of the Riemann sphere. Longitude
+// increases along the x axis from -pi to pi, and latitude decreases along the
+// y axis from pi/2 to -pi/2, so that an image of the domain wraps seamlessly
+// around a viewer.
+//
+// The sphere is mapped onto the plane by stereographic projection from its
+// north pole, scaled so that its equator becomes the circle of radius scale
+// about the origin. Without rotation, the north pole is the point at infinity,
+// the south pole is 0, and the center of the panorama is the point scale. The
+// sphere can be rotated by the yaw, pitch, and roll of the viewer: pitch
+// raises the center of the panorama toward infinity, yaw then turns it
+// counterclockwise about the origin, and roll turns the panorama about its
+// center beforehand.
+type SphereDomain struct {
+	scale  float64
+	xs, ys int
+
+	// rot maps directions in the panorama onto the sphere
+	rot [3][3]float64
+}
+
+// NewSphereDomain constructs a SphereDomain whose equator has the radius
+// scale in the plane, rotated by the angles yaw, pitch, and roll in radians.
+// The domain is sampled xSamples and ySamples times along the longitude and
+// latitude, respectively; for square samples, xSamples = 2*ySamples.
+func NewSphereDomain(scale float64, yaw, pitch, roll float64, xSamples, ySamples int) (*SphereDomain, error) {
+	if xSamples <= 0 || ySamples <= 0 {
+		return nil, errors.New("gofrac: The number of samples along any axis must be greater than zero")
+	}
+	if scale <= 0 {
+		return nil, errors.New("gofrac: the extent of a domain must be greater than zero")
+	}
+
+	sy, cy := math.Sincos(yaw)
+	sp, cp := math.Sincos(pitch)
+	sr, cr := math.Sincos(roll)
+	rz := [3][3]float64{{cy, -sy, 0}, {sy, cy, 0}, {0, 0, 1}}
+	ry := [3][3]float64{{cp, 0, -sp}, {0, 1, 0}, {sp, 0, cp}}
+	rx := [3][3]float64{{1, 0, 0}, {0, cr, -sr}, {0, sr, cr}}
+
+	return &SphereDomain{
+		scale: scale,
+		xs:    xSamples,
+		ys:    ySamples,
+		rot:   mul3(rz, mul3(ry, rx)),
+	}, nil
+}
+
+// mul3 multiplies the 3x3 matrices a and b.
+func mul3(a, b [3][3]float64) (m [3][3]float64) {
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				m[i][j] += a[i][k] * b[k][j]
+			}
+		}
+	}
+	return m
+}
+
+func (s *SphereDomain) At(i int, j int) (loc complex128, err error) {
+	if i < 0 || i >= s.xs || j < 0 || j >= s.ys {
+		return 0, errors.New("gofrac: sample is out of bounds")
+	}
+	return s.point(float64(i), float64(j)), nil
+}
+
+// point maps the fractional sample coordinates (i, j) onto the plane, or onto
+// cmplx.Inf() for the point at infinity.
+func (s *SphereDomain) point(i float64, j float64) complex128 {
+	sLon, cLon := math.Sincos(-math.Pi + 2*math.Pi*i/float64(s.xs))
+	sLat, cLat := math.Sincos(math.Pi/2 - math.Pi*j/float64(s.ys))
+	v := [3]float64{cLat * cLon, cLat * sLon, sLat}
+
+	var w [3]float64
+	for r := 0; r < 3; r++ {
+		w[r] = s.rot[r][0]*v[0] + s.rot[r][1]*v[1] + s.rot[r][2]*v[2]
+	}
+	if w[2] >= 1 {
+		return cmplx.Inf()
+	}
+	return complex(s.scale*w[0]/(1-w[2]), s.scale*w[1]/(1-w[2]))
+}
+
+func (s *SphereDomain) Dimensions() (rows int, cols int) {
+	return s.ys, s.xs
+}
+
+// PixelOf returns the sample whose cell contains loc. Every point, including
+// the point at infinity, lies within the domain, so ok is only false for NaN.
+func (s *SphereDomain) PixelOf(loc complex128) (i int, j int, ok bool) {
+	if cmplx.IsNaN(loc) {
+		return 0, 0, false
+	}
+
+	// inverse stereographic projection
+	w := [3]float64{0, 0, 1}
+	if !cmplx.IsInf(loc) {
+		loc /= complex(s.scale, 0)
+		n := real(loc)*real(loc) + imag(loc)*imag(loc)
+		w = [3]float64{2 * real(loc) / (n + 1), 2 * imag(loc) / (n + 1), (n - 1) / (n + 1)}
+	}
+
+	// rotations are inverted by their transposes
+	var v [3]float64
+	for r := 0; r < 3; r++ {
+		v[r] = s.rot[0][r]*w[0] + s.rot[1][r]*w[1] + s.rot[2][r]*w[2]
+	}
+
+	lon := math.Atan2(v[1], v[0])
+	lat := math.Asin(math.Max(-1, math.Min(1, v[2])))
+	x := math.Mod((lon+math.Pi)/(2*math.Pi)*float64(s.xs), float64(s.xs))
+	y := (math.Pi/2 - lat) / math.Pi * float64(s.ys)
+
+	// the south pole closes off the last row
+	y = math.Min(y, math.Nextafter(float64(s.ys), 0))
+	return cellOf(x, y, s.xs, s.ys)
+}
+
+// PixelBounds returns the corners of the cell of the sample (i, j). The
+// corners that touch the poles of the panorama, along the top of the first
+// row and the bottom of the last, coincide, and may be the point at infinity.
+func (s *SphereDomain) PixelBounds(i int, j int) (corners [4]complex128, err error) {
+	if i < 0 || i >= s.xs || j < 0 || j >= s.ys {
+		return corners, errors.New("gofrac: sample is out of bounds")
+	}
+	return cellCorners(i, j, s.point), nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac_test
+
+import (
+	"github.com/cfdwalrus/gofrac"
+	"image/color"
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestNewSphereDomain(t *testing.T) {
+	if _, err := gofrac.NewSphereDomain(1, 0, 0, 0, 0, 10); err == nil {
+		t.Errorf("Error not caught for zero samples")
+	}
+	if _, err := gofrac.NewSphereDomain(0, 0, 0, 0, 20, 10); err == nil {
+		t.Errorf("Error not caught for zero scale")
+	}
+
+	d, _ := gofrac.NewSphereDomain(2, 0, 0, 0, 40, 20)
+	if rows, cols := d.Dimensions(); rows != 20 || cols != 40 {
+		t.Errorf("%T: want: (20, 40), got: (%d, %d)", d, rows, cols)
+	}
+
+	// the north pole is infinity, the equator is the circle of radius 2, and
+	// the center of the panorama is 2
+	tc := []struct {
+		i, j int
+		want complex128
+	}{
+		{7, 0, cmplx.Inf()},
+		{20, 10, 2},
+		{30, 10, 2i},
+		{0, 10, -2},
+		{20, 5, complex(2*math.Cos(math.Pi/4)/(1-math.Sin(math.Pi/4)), 0)},
+	}
+	for _, c := range tc {
+		got, _ := d.At(c.i, c.j)
+		if cmplx.IsInf(c.want) {
+			if !cmplx.IsInf(got) {
+				t.Errorf("%T: At(%d, %d): want: %v, got: %v", d, c.i, c.j, c.want, got)
+			}
+			continue
+		}
+		if cmplx.Abs(got-c.want) > 1e-12 {
+			t.Errorf("%T: At(%d, %d): want: %v, got: %v", d, c.i, c.j, c.want, got)
+		}
+	}
+
+	// pitching by pi/2 brings infinity to the center of the panorama
+	up, _ := gofrac.NewSphereDomain(2, 0, math.Pi/2, 0, 40, 20)
+	if got, _ := up.At(20, 10); !cmplx.IsInf(got) && cmplx.Abs(got) < 1e12 {
+		t.Errorf("%T: At(20, 10): want: infinity, got: %v", up, got)
+	}
+
+	if _, err := d.At(40, 0); err == nil {
+		t.Errorf("%T: Error not caught for out of bounds sample", d)
+	}
+}
+
+func TestSphereDomain_PixelOf(t *testing.T) {
+	const xs, ys = 36, 18
+	for _, angles := range [][3]float64{{0, 0, 0}, {0.4, -0.7, 1.3}} {
+		d, _ := gofrac.NewSphereDomain(1.5, angles[0], angles[1], angles[2], xs, ys)
+
+		// the samples of a domain with twice the resolution lie in the
+		// middle of the cells of d
+		fine, _ := gofrac.NewSphereDomain(1.5, angles[0], angles[1], angles[2], 2*xs, 2*ys)
+		for j := 0; j < ys; j++ {
+			for i := 0; i < xs; i++ {
+				mid, _ := fine.At(2*i+1, 2*j+1)
+				if gi, gj, ok := d.PixelOf(mid); !ok || gi != i || gj != j {
+					t.Errorf("%T %v: PixelOf(%v): want: (%d, %d), got: (%d, %d, %v)", d, angles, mid, i, j, gi, gj, ok)
+				}
+			}
+		}
+
+		// the panorama wraps around seamlessly
+		for j := 1; j < ys; j++ {
+			corners, _ := d.PixelBounds(xs-1, j)
+			first, _ := d.At(0, j)
+			if cmplx.Abs(corners[1]-first) > 1e-9*math.Max(1, cmplx.Abs(first)) {
+				t.Errorf("%T %v: row %d: want: right edge %v, got: %v", d, angles, j, first, corners[1])
+			}
+		}
+
+		// the poles of the panorama belong to its first and last rows
+		north, _ := d.At(0, 0)
+		corners, _ := d.PixelBounds(0, ys-1)
+		for _, c := range []struct {
+			loc complex128
+			j   int
+		}{{north, 0}, {corners[3], ys - 1}} {
+			if _, j, ok := d.PixelOf(c.loc); !ok || j != c.j {
+				t.Errorf("%T %v: PixelOf(%v): want: row %d, got: (%d, %v)", d, angles, c.loc, c.j, j, ok)
+			}
+		}
+	}
+
+	d, _ := gofrac.NewSphereDomain(1, 0, 0, 0, xs, ys)
+	if _, j, ok := d.PixelOf(cmplx.Inf()); !ok || j != 0 {
+		t.Errorf("%T: PixelOf(Inf): want: row 0, got: (%d, %v)", d, j, ok)
+	}
+	if _, j, ok := d.PixelOf(0); !ok || j != ys-1 {
+		t.Errorf("%T: PixelOf(0): want: row %d, got: (%d, %v)", d, ys-1, j, ok)
+	}
+	if _, _, ok := d.PixelOf(cmplx.NaN()); ok {
+		t.Errorf("%T: PixelOf(NaN): want: outside", d)
+	}
+}
+
+func TestSphereDomain_GetImage(t *testing.T) {
+	d, _ := gofrac.NewSphereDomain(1, 0, 0, 0, 32, 16)
+	m := gofrac.NewMandelbrot(2)
+	pal := gofrac.NewUniformBandedPalette(color.White, color.Gray{Y: 128})
+
+	// points near infinity escape at once, and must neither be painted as
+	// the interior nor push the palettes out of range
+	plotters := []gofrac.Plotter{
+		&gofrac.SmoothedEscapeTimePlotter{},
+		&gofrac.DistanceEstimatorPlotter{},
+	}
+	for _, p := range plotters {
+		img, err := gofrac.GetImage(m, d, p, pal, 50)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 32; i++ {
+			if r, g, b, _ := img.At(i, 0).RGBA(); r == 0 && g == 0 && b == 0 {
+				t.Errorf("%T: (%d, 0): want: exterior color at infinity, got: black", p, i)
+			}
+		}
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac
+
+import (
+	"context"
+)
+
+// minSubdivision is the side length below which a rectangle is no longer
+// subdivided, but calculated sample by sample.
+const minSubdivision = 4
+
+func (o *Options) subdivide() bool {
+	return o != nil && o.Subdivide
+}
+
+// subdivider performs the Mariani-Silver algorithm on a single tile of
+// samples: the border of a rectangle is calculated, and if every sample on it
+// has the same escape time, so does every sample inside it for a connected
+// set, which is filled in without being calculated. Otherwise, the rectangle
+// is split in four and each quarter is treated the same way.
+type subdivider struct {
+	ctx     context.Context
+	results Results
+	frac    func(row, col int) (*Result, error)
+
+	// row0 and col0 locate the tile, and done records which of its samples
+	// have been set
+	row0, col0 int
+	done       [][]bool
+}
+
+// at calculates the sample (row, col) unless it has been already, and
+// returns its Result.
+func (s *subdivider) at(row, col int) (*Result, error) {
+	done := &s.done[row-s.row0][col-s.col0]
+	if !*done {
+		r, err := s.frac(row, col)
+		if err != nil {
+			return nil, &SampleError{Row: row, Col: col, Err: err}
+		}
+		s.results.set(row, col, r)
+		*done = true
+	}
+	return s.results.At(row, col), nil
+}
+
+// rect fills in the samples of the rectangle [row0, row1) x [col0, col1).
+func (s *subdivider) rect(row0, row1, col0, col1 int) error {
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	if row1-row0 < minSubdivision || col1-col0 < minSubdivision {
+		for row := row0; row < row1; row++ {
+			for col := col0; col < col1; col++ {
+				if _, err := s.at(row, col); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	first, err := s.at(row0, col0)
+	if err != nil {
+		return err
+	}
+	uniform := true
+	check := func(row, col int) error {
+		r, err := s.at(row, col)
+		if err != nil {
+			return err
+		}
+		if r.Iterations != first.Iterations || r.Converged != first.Converged {
+			uniform = false
+		}
+		return nil
+	}
+	for col := col0; col < col1; col++ {
+		if err := check(row0, col); err != nil {
+			return err
+		}
+		if err := check(row1-1, col); err != nil {
+			return err
+		}
+	}
+	for row := row0 + 1; row < row1-1; row++ {
+		if err := check(row, col0); err != nil {
+			return err
+		}
+		if err := check(row, col1-1); err != nil {
+			return err
+		}
+	}
+
+	if uniform {
+		fill := Result{Iterations: first.Iterations, Converged: first.Converged}
+		for row := row0 + 1; row < row1-1; row++ {
+			for col := col0 + 1; col < col1-1; col++ {
+				s.results.set(row, col, &fill)
+				s.done[row-s.row0][col-s.col0] = true
+			}
+		}
+		return nil
+	}
+
+	// the quarters share the middle row and column, which are calculated
+	// only once
+	rowMid, colMid := (row0+row1)/2, (col0+col1)/2
+	quarters := [4][4]int{
+		{row0, rowMid + 1, col0, colMid + 1},
+		{row0, rowMid + 1, colMid, col1},
+		{rowMid, row1, col0, colMid + 1},
+		{rowMid, row1, colMid, col1},
+	}
+	for _, q := range quarters {
+		if err := s.rect(q[0], q[1], q[2], q[3]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// subdivide calculates the samples of a grid of rows by cols samples with
+// the Mariani-Silver algorithm, one tile at a time, and stores them in
+// results.
+func subdivide(ctx context.Context, rows int, cols int, opts *Options, progress *progressTracker, results Results, frac func(row, col int) (*Result, error)) error {
+	tileSize := opts.TileSize
+	if tileSize < 1 {
+		tileSize = defaultTileSize
+	}
+
+	jobs := ScheduleTiles.jobs(rows, cols, tileSize, 0)
+	return runJobs(ctx, jobs, opts, func(ctx context.Context, j job) error {
+		s := subdivider{
+			ctx:     ctx,
+			results: results,
+			frac:    frac,
+			row0:    j.row0,
+			col0:    j.col0,
+			done:    make([][]bool, j.row1-j.row0),
+		}
+		for i := range s.done {
+			s.done[i] = make([]bool, j.col1-j.col0)
+		}
+
+		if err := s.rect(j.row0, j.row1, j.col0, j.col1); err != nil {
+			// cancellation is reported by runJobs
+			if err == ctx.Err() {
+				return nil
+			}
+			return err
+		}
+		for row := j.row0; row < j.row1; row++ {
+			progress.segmentDone(row, j.col1-j.col0)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,97 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac_test
+
+import (
+	"context"
+	"errors"
+	"github.com/cfdwalrus/gofrac"
+	"sync/atomic"
+	"testing"
+)
+
+// countingFrac counts the samples calculated by a Fraccer.
+type countingFrac struct {
+	gofrac.Fraccer
+	count int64
+}
+
+func (f *countingFrac) Frac(loc complex128) *gofrac.Result {
+	atomic.AddInt64(&f.count, 1)
+	return f.Fraccer.Frac(loc)
+}
+
+// testSubdivide compares subdivision with the full calculation, allowing for
+// up to maxMismatches samples whose escape times differ.
+func testSubdivide(t *testing.T, f gofrac.Fraccer, d gofrac.DomainReader, maxIt int, maxMismatches int) {
+	t.Helper()
+	want, err := gofrac.FracIt(d, f, maxIt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counter := &countingFrac{Fraccer: f}
+	opts := gofrac.Options{Subdivide: true, TileSize: 32}
+	got, err := gofrac.FracItOptions(context.Background(), d, counter, maxIt, &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows, cols := d.Dimensions()
+	mismatches := 0
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			w, g := want.At(row, col), got.At(row, col)
+			if w.Iterations != g.Iterations || w.Converged != g.Converged {
+				mismatches++
+				if maxMismatches == 0 {
+					t.Errorf("%T: (row, col) = (%d, %d): want: %d, got: %d", f, row, col, w.Iterations, g.Iterations)
+				}
+			}
+		}
+	}
+	if mismatches > maxMismatches {
+		t.Errorf("%T: want: at most %d mismatching samples, got: %d", f, maxMismatches, mismatches)
+	}
+	if counter.count >= int64(rows*cols) {
+		t.Errorf("%T: want: fewer than %d samples calculated, got: %d", f, rows*cols, counter.count)
+	}
+}
+
+func TestSubdivide_Mandelbrot(t *testing.T) {
+	d, _ := gofrac.NewDomain(-2, -1.2, 0.6, 1.2, 130, 120)
+	testSubdivide(t, gofrac.NewMandelbrot(2), d, 200, 0)
+}
+
+func TestSubdivide_JuliaQ(t *testing.T) {
+	// the Douady rabbit is connected
+	d, _ := gofrac.NewDomain(-1.5, -1, 1.5, 1, 120, 80)
+	testSubdivide(t, gofrac.NewJuliaQ(2, -0.12+0.75i), d, 200, 0)
+}
+
+func TestSubdivide_Filaments(t *testing.T) {
+	// filaments thinner than a sample can cross a rectangle between the
+	// samples of its border, so a few escape times differ
+	d, _ := gofrac.NewDomain(-0.76, 0.08, -0.72, 0.12, 400, 300)
+	testSubdivide(t, gofrac.NewMandelbrot(2), d, 1000, 12)
+}
+
+func TestSubdivide_SampleError(t *testing.T) {
+	dimensionsMock = func() (int, int) {
+		return 40, 40
+	}
+	// the border of a tile is always calculated
+	d := failingDomain{row: 0, col: 5}
+	opts := gofrac.Options{Subdivide: true}
+	_, err := gofrac.FracItOptions(context.Background(), d, gofrac.NewMandelbrot(2), 10, &opts)
+
+	var sampleErr *gofrac.SampleError
+	if !errors.As(err, &sampleErr) {
+		t.Fatalf("FracItOptions: want: *gofrac.SampleError, got: %v", err)
+	}
+	if sampleErr.Row != d.row || sampleErr.Col != d.col {
+		t.Errorf("%T: want: (row, col) = (%d, %d), got: (%d, %d)", sampleErr, d.row, d.col, sampleErr.Row, sampleErr.Col)
+	}
+}
@@ -0,0 +1,305 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac
+
+import (
+	"context"
+	"image/color"
+	"math"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// SampleGrid determines where the sub-samples of a supersampled pixel are
+// placed.
+type SampleGrid int
+
+const (
+	// GridRegular places the sub-samples on a regular N by N grid.
+	GridRegular SampleGrid = iota
+
+	// GridRotated rotates the regular grid by atan(1/2), so that no two
+	// sub-samples share a row or a column. Nearly horizontal and vertical
+	// edges are resolved better than by a regular grid.
+	GridRotated
+
+	// GridJittered places each sub-sample at a random position within its
+	// cell of the regular grid, which trades the regular patterns of
+	// aliasing for noise.
+	GridJittered
+)
+
+// rotatedGridAngle is the angle by which GridRotated rotates a regular grid.
+var rotatedGridAngle = math.Atan(0.5)
+
+// Supersampling configures the anti-aliasing of GetImageOptions. A zero
+// Supersampling disables it.
+type Supersampling struct {
+	// N is the number of sub-samples along each axis of a pixel, whose
+	// N*N sub-samples are averaged in linear RGB. Values less than 2 disable
+	// supersampling.
+	N int
+
+	// Grid determines where the sub-samples are placed.
+	Grid SampleGrid
+
+	// Threshold, if positive, makes the supersampling adaptive: only pixels
+	// whose color differs from that of one of their four neighbors by more
+	// than Threshold in some linear RGB channel are supersampled. Those
+	// pixels are found by a first pass at one sample per pixel, which a
+	// non-adaptive supersampling doesn't need: its first pass calculates the
+	// first sub-sample of every pixel instead.
+	Threshold float64
+}
+
+func (o *Options) supersampling() Supersampling {
+	if o == nil {
+		return Supersampling{}
+	}
+	return o.Supersample
+}
+
+// offset returns the position of the kth sub-sample of pixel p relative to
+// the sample of the pixel, in units of the distance between neighboring
+// samples. Like the cells of an InvertibleDomain, a pixel reaches from its
+// sample to the samples to its right and below it, so offsets lie in [0, 1)
+// along each axis.
+func (s Supersampling) offset(p int, k int) (u float64, v float64) {
+	n := float64(s.N)
+	col, row := float64(k%s.N), float64(k/s.N)
+
+	switch s.Grid {
+	case GridRotated:
+		// the grid is rotated about the middle of the pixel
+		u, v = (col+0.5)/n-0.5, (row+0.5)/n-0.5
+		sin, cos := math.Sincos(rotatedGridAngle)
+		u, v = u*cos-v*sin+0.5, u*sin+v*cos+0.5
+		return u - math.Floor(u), v - math.Floor(v)
+	case GridJittered:
+		h := splitMix64(uint64(p)<<32 | uint64(k))
+		ju := float64(h>>40) / (1 << 24)
+		jv := float64(h&(1<<24-1)) / (1 << 24)
+		return (col + ju) / n, (row + jv) / n
+	}
+	return (col + 0.5) / n, (row + 0.5) / n
+}
+
+// splitMix64 scrambles x, so that jittered sub-samples are random but
+// reproducible.
+func splitMix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// at returns the location of the kth sub-sample of the pixel at (row, col)
+// of d. The extent of a pixel is taken from the distances to its neighbors.
+func (s Supersampling) at(d DomainReader, row int, col int, k int) (loc complex128, err error) {
+	rows, cols := d.Dimensions()
+	loc, err = d.At(col, row)
+	if err != nil {
+		return 0, err
+	}
+
+	// the vectors to the next sample along each axis, or from the previous
+	// one at the far edges of the domain
+	step := func(di, dj int) (complex128, error) {
+		if col+di >= cols || row+dj >= rows {
+			prev, err := d.At(col-di, row-dj)
+			return loc - prev, err
+		}
+		next, err := d.At(col+di, row+dj)
+		return next - loc, err
+	}
+	var ex, ey complex128
+	if cols > 1 {
+		if ex, err = step(1, 0); err != nil {
+			return 0, err
+		}
+	}
+	if rows > 1 {
+		if ey, err = step(0, 1); err != nil {
+			return 0, err
+		}
+	}
+
+	u, v := s.offset(row*cols+col, k)
+	return loc + complex(u, 0)*ex + complex(v, 0)*ey, nil
+}
+
+// firstSubsampleDomain is a DomainReader over the first sub-sample of every
+// pixel of another domain. Calculating it instead of the domain itself saves
+// a sample per pixel when every pixel is supersampled anyway.
+type firstSubsampleDomain struct {
+	d DomainReader
+	s Supersampling
+}
+
+func (fd *firstSubsampleDomain) At(i int, j int) (loc complex128, err error) {
+	return fd.s.at(fd.d, j, i, 0)
+}
+
+func (fd *firstSubsampleDomain) Dimensions() (rows int, cols int) {
+	return fd.d.Dimensions()
+}
+
+// fracDomain returns the domain over which GetImageOptions calculates the
+// Results of d.
+func (s Supersampling) fracDomain(d DomainReader) DomainReader {
+	if s.N < 2 || s.Threshold > 0 {
+		return d
+	}
+	return &firstSubsampleDomain{d: d, s: s}
+}
+
+// linear returns the linear RGB components of c.
+func linear(c color.Color) (r, g, b float64) {
+	clr, _ := colorful.MakeColor(c)
+	return clr.LinearRgb()
+}
+
+// differs reports whether two colors differ by more than threshold in some
+// linear RGB channel.
+func differs(c1 color.Color, c2 color.Color, threshold float64) bool {
+	r1, g1, b1 := linear(c1)
+	r2, g2, b2 := linear(c2)
+	return math.Abs(r1-r2) > threshold || math.Abs(g1-g2) > threshold || math.Abs(b1-b2) > threshold
+}
+
+// selectPixels returns a mask of the pixels of bitmap that are to be
+// supersampled.
+func (s Supersampling) selectPixels(bitmap bitmap) [][]bool {
+	selected := make([][]bool, len(bitmap))
+	for row := range bitmap {
+		selected[row] = make([]bool, len(bitmap[row]))
+		for col := range bitmap[row] {
+			clr := bitmap[row][col]
+			neighbors := []pixel{{row - 1, col}, {row + 1, col}, {row, col - 1}, {row, col + 1}}
+			for _, n := range neighbors {
+				if n.row < 0 || n.row >= len(bitmap) || n.col < 0 || n.col >= len(bitmap[n.row]) {
+					continue
+				}
+				if differs(clr, bitmap[n.row][n.col], s.Threshold) {
+					selected[row][col] = true
+					break
+				}
+			}
+		}
+	}
+	return selected
+}
+
+// pixel gives the coordinates of a sample of a DomainReader.
+type pixel struct {
+	row, col int
+}
+
+// subsampler calculates the sub-samples of pixels and averages their colors.
+// The sub-samples are normalized as if they were part of the Results of the
+// image, whose accumulated histogram is hist.
+type subsampler struct {
+	f       Fraccer
+	d       DomainReader
+	s       Supersampling
+	plotter Plotter
+	palette ColorSampler
+
+	hist          []int
+	scale         float64
+	maxIterations int
+}
+
+// color returns the color of r.
+func (ss *subsampler) color(r *Result) color.Color {
+	return ss.palette.SampleColor(ss.plotter.Plot(r), ss.maxIterations)
+}
+
+// average returns the average color of the sub-samples of the pixel at
+// (row, col). If first isn't nil, it is the Result of the first sub-sample,
+// which is then not calculated again.
+func (ss *subsampler) average(row int, col int, first *Result) (color.Color, error) {
+	var r, g, b float64
+	k := 0
+	if first != nil {
+		r, g, b = linear(ss.color(first))
+		k++
+	}
+	for ; k < ss.s.N*ss.s.N; k++ {
+		loc, err := ss.s.at(ss.d, row, col, k)
+		if err != nil {
+			return nil, &SampleError{Row: row, Col: col, Err: err}
+		}
+		result := ss.f.Frac(loc)
+		setNFactor(result, ss.hist, ss.scale, ss.maxIterations)
+		lr, lg, lb := linear(ss.color(result))
+		r, g, b = r+lr, g+lg, b+lb
+	}
+
+	inv := 1 / float64(ss.s.N*ss.s.N)
+	return colorful.LinearRgb(r*inv, g*inv, b*inv).Clamped(), nil
+}
+
+// supersample renders results, which were calculated over the fracDomain of
+// d, into a bitmap of supersampled pixels. The sub-samples are calculated,
+// plotted, and averaged a pixel at a time, so that they are never stored.
+//
+// Without a Threshold, every pixel is supersampled, and results hold the
+// first sub-sample of each. Otherwise, results hold the samples of d, and
+// only the pixels selected by their colors are supersampled.
+func supersample(ctx context.Context, f Fraccer, d DomainReader, results *Results, plotter Plotter, palette ColorSampler, opts *Options) (bitmap, error) {
+	hist := calculateAccumulatedHistogram(*results)
+	ss := &subsampler{
+		f:             f,
+		d:             d,
+		s:             opts.supersampling(),
+		plotter:       plotter,
+		palette:       palette,
+		hist:          hist,
+		scale:         nFactorScale(hist, results.maxIterations),
+		maxIterations: results.maxIterations,
+	}
+
+	rows, cols := results.Dimensions()
+	if ss.s.Threshold <= 0 {
+		bitmap := NewBitmap(rows, cols)
+		progress := newProgressTracker(opts, StageRender, rows, cols)
+		err := forEachJob(ctx, rows, cols, opts, progress, func(row, col0, col1 int) error {
+			for col := col0; col < col1; col++ {
+				clr, err := ss.average(row, col, results.At(row, col))
+				if err != nil {
+					return err
+				}
+				bitmap[row][col] = clr
+			}
+			return nil
+		})
+		return bitmap, err
+	}
+
+	bitmap, err := RenderOptions(ctx, results, plotter, palette, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// the rendering stage was already reported as finished
+	selected := ss.s.selectPixels(bitmap)
+	subOpts := *opts
+	subOpts.Progress = nil
+	err = forEachJob(ctx, rows, cols, &subOpts, nil, func(row, col0, col1 int) error {
+		for col := col0; col < col1; col++ {
+			if !selected[row][col] {
+				continue
+			}
+			clr, err := ss.average(row, col, nil)
+			if err != nil {
+				return err
+			}
+			bitmap[row][col] = clr
+		}
+		return nil
+	})
+	return bitmap, err
+}
@@ -0,0 +1,131 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac_test
+
+import (
+	"context"
+	"github.com/cfdwalrus/gofrac"
+	"image"
+	"image/color"
+	"sync"
+	"testing"
+)
+
+// halfPlane is a fractal that escapes immediately in the left half of the
+// complex plane and a step later in the right half.
+type halfPlane struct {
+	gofrac.FracData
+}
+
+func (h halfPlane) Frac(loc complex128) *gofrac.Result {
+	r := gofrac.Result{C: loc}
+	if real(loc) >= 0 {
+		r.Iterations = 1
+	}
+	return &r
+}
+
+// blackWhite colors the escape time 0 black and everything else white.
+type blackWhite struct{}
+
+func (blackWhite) SampleColor(val float64, maxIterations int) color.Color {
+	if val == 0 {
+		return color.Black
+	}
+	return color.White
+}
+
+func getImage(t *testing.T, f gofrac.Fraccer, d gofrac.DomainReader, s gofrac.Supersampling) *image.RGBA {
+	t.Helper()
+	opts := gofrac.Options{Supersample: s}
+	img, err := gofrac.GetImageOptions(context.Background(), f, d, &gofrac.EscapeTimePlotter{}, blackWhite{}, 10, &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func TestGetImageOptions_Supersample(t *testing.T) {
+	// the samples lie at -1.25, -0.75, -0.25 and 0.25, and each pixel
+	// reaches to the next one, so the third pixel straddles the edge of the
+	// half plane
+	d, _ := gofrac.NewDomain(-1.25, 0, 0.75, 1, 4, 1)
+	grids := []gofrac.SampleGrid{gofrac.GridRegular, gofrac.GridRotated, gofrac.GridJittered}
+	for _, grid := range grids {
+		counter := &countingFrac{Fraccer: &halfPlane{}}
+		img := getImage(t, counter, d, gofrac.Supersampling{N: 4, Grid: grid})
+
+		// every sample calculated is one of the sub-samples
+		if want := int64(4 * 4 * 4); counter.count != want {
+			t.Errorf("%T: grid %d: want: %d samples calculated, got: %d", counter, grid, want, counter.count)
+		}
+		want := []uint8{0, 0, 188, 255}
+		for x, w := range want {
+			// jittered sub-samples are only half white on average
+			got := img.RGBAAt(x, 0).R
+			if got != w && (grid != gofrac.GridJittered || got < 150 || got > 220) {
+				t.Errorf("%T: grid %d: x = %d: want: %d, got: %d", img, grid, x, w, got)
+			}
+		}
+	}
+
+	// without supersampling, the edge is hard
+	img := getImage(t, &halfPlane{}, d, gofrac.Supersampling{N: 1})
+	if got := img.RGBAAt(2, 0).R; got != 0 {
+		t.Errorf("%T: want: %d, got: %d", img, 0, got)
+	}
+}
+
+func TestGetImageOptions_AdaptiveSupersample(t *testing.T) {
+	d, _ := gofrac.NewDomain(-1, 0, 1, 1, 8, 1)
+	counter := &countingFrac{Fraccer: &halfPlane{}}
+	s := gofrac.Supersampling{N: 2, Threshold: 0.1}
+	img := getImage(t, counter, d, s)
+
+	// only the pixels on either side of the edge are supersampled
+	if want := int64(8 + 2*4); counter.count != want {
+		t.Errorf("%T: want: %d samples calculated, got: %d", counter, want, counter.count)
+	}
+	full := getImage(t, &halfPlane{}, d, gofrac.Supersampling{N: 2})
+	for x := 0; x < 8; x++ {
+		if got, want := img.RGBAAt(x, 0), full.RGBAAt(x, 0); got != want {
+			t.Errorf("%T: x = %d: want: %v, got: %v", img, x, want, got)
+		}
+	}
+}
+
+func TestGetImageOptions_SupersampleProgress(t *testing.T) {
+	const rows = 3
+	d, _ := gofrac.NewDomain(-1, 0, 1, 1, 4, rows)
+
+	for _, threshold := range []float64{0, 0.1} {
+		var mu sync.Mutex
+		var reports []gofrac.Progress
+		opts := gofrac.Options{
+			Supersample: gofrac.Supersampling{N: 2, Threshold: threshold},
+			Progress: func(p gofrac.Progress) {
+				mu.Lock()
+				reports = append(reports, p)
+				mu.Unlock()
+			},
+		}
+		_, err := gofrac.GetImageOptions(context.Background(), &halfPlane{}, d, &gofrac.EscapeTimePlotter{}, blackWhite{}, 10, &opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the sub-samples don't restart the stages of the image
+		want := []gofrac.Stage{gofrac.StageFrac, gofrac.StageRender}
+		if len(reports) != len(want)*rows {
+			t.Fatalf("GetImageOptions: threshold %v: want: %d progress reports, got: %d", threshold, len(want)*rows, len(reports))
+		}
+		for i, p := range reports {
+			stage, completed := want[i/rows], i%rows+1
+			if p.Stage != stage || p.Completed != completed || p.Total != rows {
+				t.Errorf("%T: threshold %v: want: {%v %d/%d}, got: {%v %d/%d}", p, threshold, stage, completed, rows, p.Stage, p.Completed, p.Total)
+			}
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac
+
+import (
+	"errors"
+	"math/cmplx"
+)
+
+// TransformedDomain samples the image of another domain under a complex map.
+// Each sample of the underlying domain is passed through Map, so that e.g. a
+// rectangular Domain becomes a view of the 1/c plane of the Mandelbrot set
+// without any change to the Fraccer.
+//
+// If Inverse is set, and the underlying domain implements InvertibleDomain,
+// PixelOf and PixelBounds work as they do for the underlying domain. Inverse
+// must undo Map on the part of the plane covered by the domain. Otherwise,
+// PixelOf reports every point as outside the domain, and PixelBounds returns
+// an error.
+type TransformedDomain struct {
+	Domain  DomainReader
+	Map     CCMap
+	Inverse CCMap
+}
+
+// NewTransformedDomain constructs a TransformedDomain that samples the image
+// of d under m. inv may be nil if m has no inverse.
+func NewTransformedDomain(d DomainReader, m CCMap, inv CCMap) *TransformedDomain {
+	return &TransformedDomain{Domain: d, Map: m, Inverse: inv}
+}
+
+// NewMobiusDomain constructs a TransformedDomain that samples the image of d
+// under the Möbius transformation (a*z + b) / (c*z + d). An error is returned
+// if the transformation is degenerate, i.e. if a*d - b*c is 0.
+func NewMobiusDomain(dom DomainReader, a, b, c, d complex128) (*TransformedDomain, error) {
+	if a*d-b*c == 0 {
+		return nil, errors.New("gofrac: a Möbius transformation must satisfy ad - bc != 0")
+	}
+	m := func(z complex128) complex128 {
+		return (a*z + b) / (c*z + d)
+	}
+	inv := func(w complex128) complex128 {
+		return (d*w - b) / (a - c*w)
+	}
+	return NewTransformedDomain(dom, m, inv), nil
+}
+
+// NewInvertedDomain constructs a TransformedDomain that samples the image of d
+// under the inversion 1/z. Applied to a Mandelbrot set, it renders the 1/c
+// plane, in which the set surrounds a hole and its antennae reach outward.
+func NewInvertedDomain(d DomainReader) *TransformedDomain {
+	inv := func(z complex128) complex128 {
+		return 1 / z
+	}
+	return NewTransformedDomain(d, inv, inv)
+}
+
+// NewPowerDomain constructs a TransformedDomain that samples the image of d
+// under z^n. Since the map wraps the plane around the origin n times, its
+// inverse is the principal nth root, and PixelOf is only accurate for domains
+// whose arguments lie within (-pi/n, pi/n]. An error is returned if n is less
+// than 1.
+func NewPowerDomain(d DomainReader, n int) (*TransformedDomain, error) {
+	if n < 1 {
+		return nil, errors.New("gofrac: the power of a domain must be at least 1")
+	}
+	m := func(z complex128) complex128 {
+		w := z
+		for k := 1; k < n; k++ {
+			w *= z
+		}
+		return w
+	}
+	inv := func(w complex128) complex128 {
+		if w == 0 {
+			return 0
+		}
+		return cmplx.Pow(w, complex(1/float64(n), 0))
+	}
+	return NewTransformedDomain(d, m, inv), nil
+}
+
+// NewLambdaDomain constructs a TransformedDomain that samples the image of d
+// under c = lambda/2 - lambda^2/4, which maps the multiplier lambda of the
+// fixed point of z^2 + c to c. The main cardioid of the Mandelbrot set becomes
+// the unit disk in the lambda plane. The inverse takes the principal square
+// root, so PixelOf is only accurate for domains with re(lambda) < 1.
+func NewLambdaDomain(d DomainReader) *TransformedDomain {
+	m := func(l complex128) complex128 {
+		return l/2 - l*l/4
+	}
+	inv := func(c complex128) complex128 {
+		return 1 - cmplx.Sqrt(1-4*c)
+	}
+	return NewTransformedDomain(d, m, inv)
+}
+
+func (t *TransformedDomain) At(i int, j int) (loc complex128, err error) {
+	loc, err = t.Domain.At(i, j)
+	if err != nil {
+		return 0, err
+	}
+	return t.Map(loc), nil
+}
+
+func (t *TransformedDomain) Dimensions() (rows int, cols int) {
+	return t.Domain.Dimensions()
+}
+
+func (t *TransformedDomain) PixelOf(loc complex128) (i int, j int, ok bool) {
+	d, isInvertible := t.Domain.(InvertibleDomain)
+	if t.Inverse == nil || !isInvertible {
+		return 0, 0, false
+	}
+	return d.PixelOf(t.Inverse(loc))
+}
+
+// PixelBounds returns the images of the corners of the cell of the sample
+// (i, j) in the underlying domain. Since Map generally bends straight lines,
+// the corners only approximate the cell, and do so better for smaller cells.
+func (t *TransformedDomain) PixelBounds(i int, j int) (corners [4]complex128, err error) {
+	d, isInvertible := t.Domain.(InvertibleDomain)
+	if t.Inverse == nil || !isInvertible {
+		return corners, errors.New("gofrac: domain is not invertible")
+	}
+	corners, err = d.PixelBounds(i, j)
+	if err != nil {
+		return corners, err
+	}
+	for k := range corners {
+		corners[k] = t.Map(corners[k])
+	}
+	return corners, nil
+}
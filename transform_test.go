@@ -0,0 +1,76 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac_test
+
+import (
+	"github.com/cfdwalrus/gofrac"
+	"math/cmplx"
+	"testing"
+)
+
+func TestTransformedDomain(t *testing.T) {
+	base, _ := gofrac.NewDomain(0.5, 0.5, 2, 1.5, 30, 20)
+
+	if _, err := gofrac.NewMobiusDomain(base, 1, 2, 2, 4); err == nil {
+		t.Errorf("Error not caught for degenerate Möbius transformation")
+	}
+	if _, err := gofrac.NewPowerDomain(base, 0); err == nil {
+		t.Errorf("Error not caught for zero power")
+	}
+
+	mobius, _ := gofrac.NewMobiusDomain(base, 1, 1i, 1, -3)
+	square, _ := gofrac.NewPowerDomain(base, 2)
+	inverted := gofrac.NewInvertedDomain(base)
+	for _, d := range []*gofrac.TransformedDomain{mobius, square, inverted} {
+		if rows, cols := d.Dimensions(); rows != 20 || cols != 30 {
+			t.Errorf("%T: want: (20, 30), got: (%d, %d)", d, rows, cols)
+		}
+		z, _ := base.At(7, 11)
+		loc, _ := d.At(7, 11)
+		if loc != d.Map(z) {
+			t.Errorf("%T: At(7, 11): want: %v, got: %v", d, d.Map(z), loc)
+		}
+		if w := d.Inverse(loc); cmplx.Abs(w-z) > 1e-12 {
+			t.Errorf("%T: Inverse(%v): want: %v, got: %v", d, loc, z, w)
+		}
+		checkInvertible(t, d)
+	}
+
+	if _, err := inverted.At(30, 0); err == nil {
+		t.Errorf("%T: Error not caught for out of bounds sample", inverted)
+	}
+
+	// without an inverse, no point can be found
+	opaque := gofrac.NewTransformedDomain(base, cmplx.Exp, nil)
+	if _, _, ok := opaque.PixelOf(1); ok {
+		t.Errorf("%T: PixelOf: want: outside for a domain without an inverse", opaque)
+	}
+	if _, err := opaque.PixelBounds(0, 0); err == nil {
+		t.Errorf("%T: Error not caught for a domain without an inverse", opaque)
+	}
+}
+
+func TestNewLambdaDomain(t *testing.T) {
+	base, _ := gofrac.NewDomain(-0.9, -0.6, 0.6, 0.6, 24, 16)
+	d := gofrac.NewLambdaDomain(base)
+	checkInvertible(t, d)
+
+	// the unit disk of the lambda plane is the main cardioid
+	m := gofrac.NewMandelbrot(2)
+	_ = m.SetMaxIterations(200)
+	rows, cols := d.Dimensions()
+	for j := 0; j < rows; j++ {
+		for i := 0; i < cols; i++ {
+			l, _ := base.At(i, j)
+			if cmplx.Abs(l) >= 0.95 {
+				continue
+			}
+			c, _ := d.At(i, j)
+			if r := m.Frac(c); r.Iterations != 199 {
+				t.Errorf("%T: lambda = %v: want: %v in the main cardioid, got: escape after %d", d, l, c, r.Iterations)
+			}
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/cmplx"
+)
+
+// OrbitTrap is a shape in the complex plane that catches the iterates of a
+// fractal calculation. Every iterate is measured against the traps in
+// FracData, and the closest approach is recorded in the Result.
+type OrbitTrap interface {
+	// Distance returns the distance of z to the trap. It may return +Inf if
+	// z can't be caught by the trap at all.
+	Distance(z complex128) float64
+}
+
+// PointTrap catches iterates near a single point.
+type PointTrap struct {
+	Point complex128
+}
+
+func (t PointTrap) Distance(z complex128) float64 {
+	return cmplx.Abs(z - t.Point)
+}
+
+// LineTrap catches iterates near the line through Point in the direction
+// given by Direction, which must be nonzero.
+type LineTrap struct {
+	Point     complex128
+	Direction complex128
+}
+
+func (t LineTrap) Distance(z complex128) float64 {
+	// the component of z - Point perpendicular to the line
+	return math.Abs(imag((z-t.Point)*cmplx.Conj(t.Direction))) / cmplx.Abs(t.Direction)
+}
+
+// CircleTrap catches iterates near the circle of radius Radius around Center.
+type CircleTrap struct {
+	Center complex128
+	Radius float64
+}
+
+func (t CircleTrap) Distance(z complex128) float64 {
+	return math.Abs(cmplx.Abs(z-t.Center) - t.Radius)
+}
+
+// CrossTrap catches iterates near the horizontal and vertical lines through
+// Center.
+type CrossTrap struct {
+	Center complex128
+}
+
+func (t CrossTrap) Distance(z complex128) float64 {
+	d := z - t.Center
+	return math.Min(math.Abs(real(d)), math.Abs(imag(d)))
+}
+
+// PickoverStalks is a cross trap that only catches iterates within Width of
+// its arms. Seen through it, the orbits of a fractal grow the thin, hairy
+// "stalks" described by Clifford Pickover.
+type PickoverStalks struct {
+	Center complex128
+	Width  float64
+}
+
+func (t PickoverStalks) Distance(z complex128) float64 {
+	d := CrossTrap{t.Center}.Distance(z)
+	if d > t.Width {
+		return math.Inf(1)
+	}
+	return d
+}
+
+// ImageTrap catches iterates that land on an image placed in the complex
+// plane. The image is centered on Center and is Width wide, and its height
+// follows from its aspect ratio. The distance of an iterate landing on the
+// image is 1 minus the brightness of the pixel underneath, so that bright
+// pixels catch iterates most strongly; the color of that pixel is given by
+// Color.
+type ImageTrap struct {
+	Image  image.Image
+	Center complex128
+	Width  float64
+}
+
+// Color returns the color of the image at z, or color.Transparent if z lies
+// outside the image.
+func (t ImageTrap) Color(z complex128) color.Color {
+	b := t.Image.Bounds()
+	if b.Empty() || t.Width <= 0 {
+		return color.Transparent
+	}
+
+	scale := float64(b.Dx()) / t.Width
+	d := z - t.Center
+	x := int(math.Floor(real(d)*scale + 0.5*float64(b.Dx())))
+	y := int(math.Floor(0.5*float64(b.Dy()) - imag(d)*scale))
+	if x < 0 || x >= b.Dx() || y < 0 || y >= b.Dy() {
+		return color.Transparent
+	}
+	return t.Image.At(b.Min.X+x, b.Min.Y+y)
+}
+
+func (t ImageTrap) Distance(z complex128) float64 {
+	clr := t.Color(z)
+	_, _, _, a := clr.RGBA()
+	if a == 0 {
+		return math.Inf(1)
+	}
+	// brightness of the premultiplied color, so transparent pixels are dim
+	gray := color.Gray16Model.Convert(clr).(color.Gray16)
+	return 1 - float64(gray.Y)/0xffff
+}
+
+// trapPlot plots a Result with respect to the orbit traps of a fractal. Results
+// that were never caught by a trap, or that carry no trap statistics, are
+// plotted as part of the set.
+func (pb *PlotterBase) trapPlot(r *Result, pFunc func(r *Result) float64) float64 {
+	if s := r.Stats; s == nil || s.TrapIndex < 0 || math.IsInf(s.TrapDistance, 1) {
+		return float64(pb.MaxIterations - 1)
+	}
+	return pFunc(r)
+}
+
+// OrbitTrapPlotter plots a Result by the closest approach of its orbit to
+// the orbit traps of a fractal. Unlike most plotters, it plots points inside
+// the set as well as those that escaped.
+type OrbitTrapPlotter struct {
+	PlotterBase
+
+	// Scale is the distance from a trap at which a Result is plotted halfway
+	// up the palette. If it is zero, it defaults to 1.
+	Scale float64
+}
+
+func (p OrbitTrapPlotter) Plot(r *Result) float64 {
+	return p.trapPlot(r, func(r *Result) float64 {
+		scale := p.Scale
+		if scale == 0 {
+			scale = 1
+		}
+		t := r.Stats.TrapDistance / scale
+		return float64(p.MaxIterations-2) * t / (1 + t)
+	})
+}
+
+// OrbitTrapIterationPlotter plots a Result by the iteration at which its
+// orbit came closest to a trap.
+type OrbitTrapIterationPlotter struct {
+	PlotterBase
+}
+
+func (p OrbitTrapIterationPlotter) Plot(r *Result) float64 {
+	return p.trapPlot(r, func(r *Result) float64 {
+		return math.Min(float64(r.Stats.TrapIteration), float64(p.MaxIterations-2))
+	})
+}
+
+// OrbitTrapAnglePlotter plots a Result by the phase of the iterate that came
+// closest to a trap.
+type OrbitTrapAnglePlotter struct {
+	PlotterBase
+}
+
+func (p OrbitTrapAnglePlotter) Plot(r *Result) float64 {
+	return p.trapPlot(r, func(r *Result) float64 {
+		return float64(p.MaxIterations-2) * (cmplx.Phase(r.Stats.TrapPoint) + math.Pi) / (2 * math.Pi)
+	})
+}
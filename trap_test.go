@@ -0,0 +1,94 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac_test
+
+import (
+	"github.com/cfdwalrus/gofrac"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestOrbitTrap_Distance(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 2, 2))
+	img.SetGray(0, 0, color.Gray{Y: 255})
+	img.SetGray(1, 0, color.Gray{Y: 0})
+
+	tests := []struct {
+		trap gofrac.OrbitTrap
+		z    complex128
+		want float64
+	}{
+		{gofrac.PointTrap{Point: 1 + 1i}, 4 + 5i, 5},
+		{gofrac.LineTrap{Point: 1i, Direction: 2}, 3 + 4i, 3},
+		{gofrac.LineTrap{Point: 0, Direction: 1 + 1i}, 2, math.Sqrt2},
+		{gofrac.CircleTrap{Center: 1, Radius: 2}, 1 + 0.5i, 1.5},
+		{gofrac.CircleTrap{Center: 1, Radius: 2}, 4, 1},
+		{gofrac.CrossTrap{Center: 1i}, 3 + 1.5i, 0.5},
+		{gofrac.PickoverStalks{Center: 0, Width: 0.1}, 3 + 0.05i, 0.05},
+		{gofrac.PickoverStalks{Center: 0, Width: 0.1}, 3 + 0.5i, math.Inf(1)},
+		// the image covers [-1, 1] x [-1, 1], with its top-left pixel white
+		// and top-right one black
+		{gofrac.ImageTrap{Image: img, Center: 0, Width: 2}, -0.5 + 0.5i, 0},
+		{gofrac.ImageTrap{Image: img, Center: 0, Width: 2}, 0.5 + 0.5i, 1},
+		{gofrac.ImageTrap{Image: img, Center: 0, Width: 2}, 1.5, math.Inf(1)},
+	}
+	for _, test := range tests {
+		if got := test.trap.Distance(test.z); math.Abs(got-test.want) > 1e-12 && got != test.want {
+			t.Errorf("%T: z = %v: want: %v, got: %v", test.trap, test.z, test.want, got)
+		}
+	}
+}
+
+func TestMandelbrot_Traps(t *testing.T) {
+	m := gofrac.NewMandelbrot(2)
+	m.SetMaxIterations(50)
+	m.SetTraps(gofrac.CircleTrap{Radius: 10}, gofrac.PointTrap{Point: -1})
+
+	// the orbit of -1 is 0, -1, 0, -1, ..., which first hits the point trap
+	// at z_1 = -1
+	r := statsOf(m.Frac(-1))
+	if r.TrapIndex != 1 || r.TrapIteration != 1 || r.TrapDistance != 0 || r.TrapPoint != -1 {
+		t.Errorf("%T: want: trap 1 at iteration 1, got: trap %d at iteration %d (distance %v)", m, r.TrapIndex, r.TrapIteration, r.TrapDistance)
+	}
+
+	// without traps, nothing is recorded
+	m.SetTraps()
+	if r := m.Frac(-1); r.Stats != nil {
+		t.Errorf("%T: want: no trap data, got: %+v", m, *r.Stats)
+	}
+}
+
+func TestOrbitTrapPlotter_Plot(t *testing.T) {
+	f := gofrac.FracData{Radius: 2, MaxIterations: 102}
+
+	p := gofrac.OrbitTrapPlotter{Scale: 0.5}
+	p.SetFracData(&f)
+	r := gofrac.Result{Iterations: 101, Stats: &gofrac.Statistics{TrapDistance: 0.5, TrapIndex: 0}}
+	if got, want := p.Plot(&r), 50.0; got != want {
+		t.Errorf("%T: want: %0.2f, got: %0.2f", p, want, got)
+	}
+
+	// orbits that weren't caught are plotted as part of the set
+	r = gofrac.Result{Iterations: 5, Stats: &gofrac.Statistics{TrapDistance: math.Inf(1), TrapIndex: -1}}
+	if got, want := p.Plot(&r), 101.0; got != want {
+		t.Errorf("%T: want: %0.2f, got: %0.2f", p, want, got)
+	}
+
+	ip := gofrac.OrbitTrapIterationPlotter{}
+	ip.SetFracData(&f)
+	r = gofrac.Result{Stats: &gofrac.Statistics{TrapIteration: 7, TrapIndex: 0}}
+	if got, want := ip.Plot(&r), 7.0; got != want {
+		t.Errorf("%T: want: %0.2f, got: %0.2f", ip, want, got)
+	}
+
+	ap := gofrac.OrbitTrapAnglePlotter{}
+	ap.SetFracData(&f)
+	r = gofrac.Result{Stats: &gofrac.Statistics{TrapPoint: 1, TrapIndex: 0}}
+	if got, want := ap.Plot(&r), 50.0; got != want {
+		t.Errorf("%T: want: %0.2f, got: %0.2f", ap, want, got)
+	}
+}
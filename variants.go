@@ -0,0 +1,227 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac
+
+import "math"
+
+// The fractals in this file are variants of the Mandelbrot set and quadratic
+// Julia sets in which the real and/or imaginary parts of the iterate are
+// replaced with their absolute values, or the iterate is conjugated, before or
+// after squaring. Since they all remain quadratic, they share the bailout
+// radius, maximum iteration count, and smoothing behavior of Quadratic.
+//
+// Each comes in a Mandelbrot-style form, which iterates z_{n+1} = V(z_n) + c
+// for every c in the domain with z_0 = 0, and a Julia-style form, which
+// iterates z_{n+1} = V(z_n) + C for every z_0 in the domain and a given C.
+
+// burningShip computes (|Re(z)| + i|Im(z)|)^2.
+func burningShip(z complex128) complex128 {
+	x, y := math.Abs(real(z)), math.Abs(imag(z))
+	return complex(x*x-y*y, 2*x*y)
+}
+
+// tricorn computes conj(z)^2.
+func tricorn(z complex128) complex128 {
+	x, y := real(z), imag(z)
+	return complex(x*x-y*y, -2*x*y)
+}
+
+// celtic computes |Re(z^2)| + i Im(z^2).
+func celtic(z complex128) complex128 {
+	x, y := real(z), imag(z)
+	return complex(math.Abs(x*x-y*y), 2*x*y)
+}
+
+// buffalo computes |Re(z^2)| + i|Im(z^2)|.
+func buffalo(z complex128) complex128 {
+	x, y := real(z), imag(z)
+	return complex(math.Abs(x*x-y*y), math.Abs(2*x*y))
+}
+
+// perpendicular computes Re(z^2) - 2i|Re(z)|Im(z).
+func perpendicular(z complex128) complex128 {
+	x, y := real(z), imag(z)
+	return complex(x*x-y*y, -2*math.Abs(x)*y)
+}
+
+// BurningShip is the Burning Ship fractal, which results from iterating
+// z_{n+1} = (|Re(z_n)| + i|Im(z_n)|)^2 + c. With the imaginary axis pointing
+// up, the eponymous ship is upside down.
+type BurningShip struct {
+	Quadratic
+}
+
+// NewBurningShip constructs a BurningShip struct with a given bailout radius.
+func NewBurningShip(radius float64) *BurningShip {
+	return &BurningShip{
+		Quadratic: NewQuadratic(radius),
+	}
+}
+
+func (b BurningShip) Frac(loc complex128) *Result {
+	return b.iterate(0, loc, burningShip)
+}
+
+// BurningShipJulia is the Julia-style counterpart of BurningShip.
+type BurningShipJulia struct {
+	Quadratic
+	C complex128
+}
+
+// NewBurningShipJulia constructs a BurningShipJulia struct with a given
+// bailout radius and complex parameter c.
+func NewBurningShipJulia(radius float64, c complex128) *BurningShipJulia {
+	return &BurningShipJulia{
+		Quadratic: NewQuadratic(radius),
+		C:         c,
+	}
+}
+
+func (j BurningShipJulia) Frac(loc complex128) *Result {
+	return j.iterate(loc, j.C, burningShip)
+}
+
+// Tricorn, also known as the Mandelbar set, results from iterating
+// z_{n+1} = conj(z_n)^2 + c.
+type Tricorn struct {
+	Quadratic
+}
+
+// NewTricorn constructs a Tricorn struct with a given bailout radius.
+func NewTricorn(radius float64) *Tricorn {
+	return &Tricorn{
+		Quadratic: NewQuadratic(radius),
+	}
+}
+
+func (t Tricorn) Frac(loc complex128) *Result {
+	return t.iterate(0, loc, tricorn)
+}
+
+// TricornJulia is the Julia-style counterpart of Tricorn.
+type TricornJulia struct {
+	Quadratic
+	C complex128
+}
+
+// NewTricornJulia constructs a TricornJulia struct with a given bailout radius
+// and complex parameter c.
+func NewTricornJulia(radius float64, c complex128) *TricornJulia {
+	return &TricornJulia{
+		Quadratic: NewQuadratic(radius),
+		C:         c,
+	}
+}
+
+func (j TricornJulia) Frac(loc complex128) *Result {
+	return j.iterate(loc, j.C, tricorn)
+}
+
+// Celtic results from iterating z_{n+1} = |Re(z_n^2)| + i Im(z_n^2) + c.
+type Celtic struct {
+	Quadratic
+}
+
+// NewCeltic constructs a Celtic struct with a given bailout radius.
+func NewCeltic(radius float64) *Celtic {
+	return &Celtic{
+		Quadratic: NewQuadratic(radius),
+	}
+}
+
+func (c Celtic) Frac(loc complex128) *Result {
+	return c.iterate(0, loc, celtic)
+}
+
+// CelticJulia is the Julia-style counterpart of Celtic.
+type CelticJulia struct {
+	Quadratic
+	C complex128
+}
+
+// NewCelticJulia constructs a CelticJulia struct with a given bailout radius
+// and complex parameter c.
+func NewCelticJulia(radius float64, c complex128) *CelticJulia {
+	return &CelticJulia{
+		Quadratic: NewQuadratic(radius),
+		C:         c,
+	}
+}
+
+func (j CelticJulia) Frac(loc complex128) *Result {
+	return j.iterate(loc, j.C, celtic)
+}
+
+// Buffalo results from iterating z_{n+1} = |Re(z_n^2)| + i|Im(z_n^2)| + c.
+type Buffalo struct {
+	Quadratic
+}
+
+// NewBuffalo constructs a Buffalo struct with a given bailout radius.
+func NewBuffalo(radius float64) *Buffalo {
+	return &Buffalo{
+		Quadratic: NewQuadratic(radius),
+	}
+}
+
+func (b Buffalo) Frac(loc complex128) *Result {
+	return b.iterate(0, loc, buffalo)
+}
+
+// BuffaloJulia is the Julia-style counterpart of Buffalo.
+type BuffaloJulia struct {
+	Quadratic
+	C complex128
+}
+
+// NewBuffaloJulia constructs a BuffaloJulia struct with a given bailout radius
+// and complex parameter c.
+func NewBuffaloJulia(radius float64, c complex128) *BuffaloJulia {
+	return &BuffaloJulia{
+		Quadratic: NewQuadratic(radius),
+		C:         c,
+	}
+}
+
+func (j BuffaloJulia) Frac(loc complex128) *Result {
+	return j.iterate(loc, j.C, buffalo)
+}
+
+// Perpendicular, or the Perpendicular Mandelbrot set, results from iterating
+// z_{n+1} = Re(z_n^2) - 2i|Re(z_n)|Im(z_n) + c.
+type Perpendicular struct {
+	Quadratic
+}
+
+// NewPerpendicular constructs a Perpendicular struct with a given bailout
+// radius.
+func NewPerpendicular(radius float64) *Perpendicular {
+	return &Perpendicular{
+		Quadratic: NewQuadratic(radius),
+	}
+}
+
+func (p Perpendicular) Frac(loc complex128) *Result {
+	return p.iterate(0, loc, perpendicular)
+}
+
+// PerpendicularJulia is the Julia-style counterpart of Perpendicular.
+type PerpendicularJulia struct {
+	Quadratic
+	C complex128
+}
+
+// NewPerpendicularJulia constructs a PerpendicularJulia struct with a given
+// bailout radius and complex parameter c.
+func NewPerpendicularJulia(radius float64, c complex128) *PerpendicularJulia {
+	return &PerpendicularJulia{
+		Quadratic: NewQuadratic(radius),
+		C:         c,
+	}
+}
+
+func (j PerpendicularJulia) Frac(loc complex128) *Result {
+	return j.iterate(loc, j.C, perpendicular)
+}
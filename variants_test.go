@@ -0,0 +1,60 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac_test
+
+import (
+	"github.com/cfdwalrus/gofrac"
+	"testing"
+)
+
+func TestVariants_Frac(t *testing.T) {
+	c := 0.25 - 0.5i
+	z := -1.5 + 2i // z^2 = -1.75 - 6i
+
+	tc := []struct {
+		f    gofrac.Fraccer
+		want complex128
+	}{
+		{gofrac.NewBurningShipJulia(100, c), complex(-1.75, 6) + c},
+		{gofrac.NewTricornJulia(100, c), complex(-1.75, 6) + c},
+		{gofrac.NewCelticJulia(100, c), complex(1.75, -6) + c},
+		{gofrac.NewBuffaloJulia(100, c), complex(1.75, 6) + c},
+		{gofrac.NewPerpendicularJulia(100, c), complex(-1.75, -6) + c},
+	}
+
+	// a single iteration applies the map once
+	for _, tc := range tc {
+		tc.f.SetMaxIterations(1)
+		got := tc.f.Frac(z)
+		if got.Z != tc.want || got.C != c {
+			t.Errorf("%T: want: Z = %v, C = %v, got: Z = %v, C = %v", tc.f, tc.want, c, got.Z, got.C)
+		}
+	}
+}
+
+func TestVariants_Convergence(t *testing.T) {
+	maxIt := 100
+	mandelStyle := []gofrac.Fraccer{
+		gofrac.NewBurningShip(4),
+		gofrac.NewTricorn(4),
+		gofrac.NewCeltic(4),
+		gofrac.NewBuffalo(4),
+		gofrac.NewPerpendicular(4),
+	}
+
+	for _, f := range mandelStyle {
+		f.SetMaxIterations(maxIt)
+
+		// the origin never leaves the origin
+		if got := f.Frac(0); got.Iterations != maxIt-1 {
+			t.Errorf("%T: c = 0: want: %d iterations, got: %d", f, maxIt-1, got.Iterations)
+		}
+
+		// far away points escape immediately
+		if got := f.Frac(10 + 10i); got.Iterations >= maxIt-1 {
+			t.Errorf("%T: c = 10+10i: want: < %d iterations, got: %d", f, maxIt-1, got.Iterations)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// Schedule determines how the samples of a calculation are divided into jobs
+// for the workers.
+type Schedule int
+
+const (
+	// ScheduleRows makes each row of samples a separate job.
+	ScheduleRows Schedule = iota
+
+	// ScheduleTiles divides the samples into square tiles whose side length
+	// is given by Options.TileSize.
+	ScheduleTiles
+
+	// ScheduleInterleaved gives each of n workers every nth row, starting
+	// from a different offset. Expensive regions of an image are thereby
+	// shared evenly between the workers with very little coordination.
+	ScheduleInterleaved
+)
+
+// defaultTileSize is the tile side length used by ScheduleTiles when
+// Options.TileSize isn't set.
+const defaultTileSize = 64
+
+// job is a unit of work: the rows row0, row0+stride, ... below row1, each
+// restricted to the columns [col0, col1).
+type job struct {
+	row0, row1, stride int
+	col0, col1         int
+}
+
+// jobs divides a grid of samples into jobs according to the schedule.
+func (s Schedule) jobs(rows int, cols int, tileSize int, workers int) []job {
+	var jobs []job
+	switch s {
+	case ScheduleTiles:
+		for row := 0; row < rows; row += tileSize {
+			for col := 0; col < cols; col += tileSize {
+				jobs = append(jobs, job{
+					row0:   row,
+					row1:   minInt(row+tileSize, rows),
+					stride: 1,
+					col0:   col,
+					col1:   minInt(col+tileSize, cols),
+				})
+			}
+		}
+	case ScheduleInterleaved:
+		for offset := 0; offset < minInt(workers, rows); offset++ {
+			jobs = append(jobs, job{row0: offset, row1: rows, stride: workers, col1: cols})
+		}
+	default:
+		for row := 0; row < rows; row++ {
+			jobs = append(jobs, job{row0: row, row1: row + 1, stride: 1, col1: cols})
+		}
+	}
+	return jobs
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// Pool is a fixed set of worker goroutines. Any number of concurrent
+// calculations may share a Pool via Options.Pool, in which case their jobs
+// are interleaved and the total number of busy workers never exceeds the
+// size of the Pool.
+type Pool struct {
+	tasks     chan func()
+	workers   int
+	closeOnce sync.Once
+}
+
+// NewPool starts a Pool of the given number of workers. If workers is less
+// than one, runtime.NumCPU() workers are started.
+func NewPool(workers int) *Pool {
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+
+	p := &Pool{
+		tasks:   make(chan func()),
+		workers: workers,
+	}
+	for worker := 0; worker < workers; worker++ {
+		go func() {
+			for task := range p.tasks {
+				task()
+			}
+		}()
+	}
+	return p
+}
+
+// Workers returns the number of workers in the Pool.
+func (p *Pool) Workers() int {
+	return p.workers
+}
+
+// Close stops the workers of the Pool once they have finished the tasks
+// already given to them. A Pool must not be used after it has been closed.
+func (p *Pool) Close() {
+	p.closeOnce.Do(func() {
+		close(p.tasks)
+	})
+}
+
+// submit hands task to the next free worker. It gives up and returns false
+// if ctx is cancelled first.
+func (p *Pool) submit(ctx context.Context, task func()) bool {
+	select {
+	case p.tasks <- task:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (o *Options) workers() int {
+	switch {
+	case o == nil:
+		return runtime.NumCPU()
+	case o.Pool != nil:
+		return o.Pool.Workers()
+	case o.Workers > 0:
+		return o.Workers
+	}
+	return runtime.NumCPU()
+}
+
+func (o *Options) jobs(rows int, cols int) []job {
+	if o == nil {
+		return ScheduleRows.jobs(rows, cols, 0, 0)
+	}
+
+	tileSize := o.TileSize
+	if tileSize < 1 {
+		tileSize = defaultTileSize
+	}
+	return o.Schedule.jobs(rows, cols, tileSize, o.workers())
+}
+
+func (o *Options) pool() *Pool {
+	if o == nil {
+		return nil
+	}
+	return o.Pool
+}
+
+// forEachJob divides a grid of rows by cols samples into jobs as configured
+// by opts and calls fn for every row segment [col0, col1) of every job,
+// spreading the jobs across the workers. Completed rows are reported to
+// progress, which may be nil.
+//
+// If fn returns an error, the remaining workers are stopped and the first such
+// error is returned. Likewise, once ctx is cancelled, the workers stop picking
+// up new jobs and ctx.Err() is returned.
+func forEachJob(ctx context.Context, rows int, cols int, opts *Options, progress *progressTracker, fn func(row, col0, col1 int) error) error {
+	return runJobs(ctx, opts.jobs(rows, cols), opts, func(ctx context.Context, j job) error {
+		for row := j.row0; row < j.row1; row += j.stride {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err := fn(row, j.col0, j.col1); err != nil {
+				return err
+			}
+			progress.segmentDone(row, j.col1-j.col0)
+		}
+		return nil
+	})
+}
+
+// runJobs calls run for every job, spreading the jobs across the workers
+// configured by opts. It stops the workers and returns the first error
+// returned by run, or ctx.Err() once ctx is cancelled.
+func runJobs(ctx context.Context, jobs []job, opts *Options, run func(ctx context.Context, j job) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var firstErr error
+	errOnce := sync.Once{}
+
+	do := func(j job) {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := run(ctx, j); err != nil {
+			errOnce.Do(func() {
+				firstErr = err
+				cancel()
+			})
+		}
+	}
+
+	wg := sync.WaitGroup{}
+
+	if pool := opts.pool(); pool != nil {
+		for _, j := range jobs {
+			wg.Add(1)
+			task := func(j job) func() {
+				return func() {
+					defer wg.Done()
+					do(j)
+				}
+			}(j)
+			if !pool.submit(ctx, task) {
+				wg.Done()
+				break
+			}
+		}
+	} else {
+		jobCh := make(chan job, len(jobs))
+		for _, j := range jobs {
+			jobCh <- j
+		}
+		close(jobCh)
+
+		for worker := 0; worker < opts.workers(); worker++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := range jobCh {
+					do(j)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
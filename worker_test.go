@@ -0,0 +1,69 @@
+// Copyright 2020 Andrew Quinn. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gofrac_test
+
+import (
+	"context"
+	"github.com/cfdwalrus/gofrac"
+	"sync"
+	"testing"
+)
+
+func TestFracItOptions_Schedules(t *testing.T) {
+	d, _ := gofrac.NewDomain(-2.5, -1.0, 1.0, 1.0, 70, 45)
+	maxIt := 50
+	want, err := gofrac.FracIt(d, gofrac.NewMandelbrot(4), maxIt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool := gofrac.NewPool(3)
+	defer pool.Close()
+
+	tc := []gofrac.Options{
+		{Schedule: gofrac.ScheduleRows, Workers: 1},
+		{Schedule: gofrac.ScheduleTiles, TileSize: 16},
+		{Schedule: gofrac.ScheduleTiles, Workers: 2},
+		{Schedule: gofrac.ScheduleInterleaved, Workers: 4},
+		{Schedule: gofrac.ScheduleTiles, TileSize: 8, Pool: pool},
+		{Schedule: gofrac.ScheduleInterleaved, Pool: pool},
+	}
+	for _, opts := range tc {
+		got, err := gofrac.FracItOptions(context.Background(), d, gofrac.NewMandelbrot(4), maxIt, &opts)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		rows, cols := want.Dimensions()
+		for row := 0; row < rows; row++ {
+			for col := 0; col < cols; col++ {
+				if !cmpResult(*want.At(row, col), *got.At(row, col)) {
+					t.Errorf("%+v: (row, col) = (%d, %d): want: %v, got: %v", opts, row, col, *want.At(row, col), *got.At(row, col))
+				}
+			}
+		}
+	}
+}
+
+func TestPool_Shared(t *testing.T) {
+	pool := gofrac.NewPool(2)
+	defer pool.Close()
+
+	d, _ := gofrac.NewDomain(-1.6, -1.0, 1.6, 1.0, 40, 30)
+	opts := &gofrac.Options{Pool: pool, Schedule: gofrac.ScheduleTiles, TileSize: 7}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := gofrac.FracItOptions(context.Background(), d, gofrac.NewJuliaQ(4, -0.8+0.156i), 30, opts)
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+}